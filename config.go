@@ -0,0 +1,247 @@
+package simple
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ConfigOrigin is the layer a config field got its value from
+type ConfigOrigin string
+
+const (
+	OriginNone    ConfigOrigin = "none"
+	OriginDefault ConfigOrigin = "default"
+	OriginFile    ConfigOrigin = "file"
+	OriginEnv     ConfigOrigin = "env"
+	OriginFlag    ConfigOrigin = "flag"
+)
+
+// configField is a leaf field of a config struct
+type configField struct {
+	path  string   // Go path, such as DB.MaxConns
+	names []string // snake case path, such as [db max_conns]
+	field reflect.StructField
+	value reflect.Value
+}
+
+// configFlag sets a config field from the command line
+type configFlag struct {
+	field   configField
+	origins map[string]ConfigOrigin
+}
+
+func (f *configFlag) String() string { return "" }
+
+func (f *configFlag) Set(raw string) error {
+	if err := setEnvValue(f.field.value, raw); err != nil {
+		return err
+	}
+	f.origins[f.field.path] = OriginFlag
+	return nil
+}
+
+func (f *configFlag) IsBoolFlag() bool { return f.field.value.Kind() == reflect.Bool }
+
+// LoadConfig builds a config of type `T` from several layers, each one overriding the previous :
+//   - the `default` tag of the fields
+//   - the base config file `filePath`, parsed with FileRegistry. Use an empty path to skip it
+//   - environment variables named after the field path, such as APP_DB__MAX_CONNS for DB.MaxConns with the prefix APP_.
+//     Use a nil env to read the process environment
+//   - command-line flags generated from the struct, such as -db.max-conns, parsed from args (e.g. os.Args[1:])
+//
+// The config is then validated with validator package. Values of secret fields are redacted from env errors.
+// The origins map gives the layer of every field, by Go path (e.g. DB.MaxConns).
+func LoadConfig[T any](filePath string, env *Env, envPrefix string, args []string) (*T, map[string]ConfigOrigin, error) {
+	var config T
+	root := reflect.ValueOf(&config).Elem()
+	if root.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("config: %T is not a struct", config)
+	}
+
+	origins := make(map[string]ConfigOrigin)
+	for _, field := range configFields(root, "", nil) {
+		origins[field.path] = OriginNone
+		if def, ok := field.field.Tag.Lookup("default"); ok {
+			if err := setEnvValue(field.value, def); err != nil {
+				return nil, nil, fmt.Errorf("invalid default for %s: %w", field.path, err)
+			}
+			origins[field.path] = OriginDefault
+		}
+	}
+
+	if filePath != "" {
+		if err := loadConfigFile(filePath, &config, root, origins); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	lookup := os.LookupEnv
+	if env != nil {
+		lookup = env.Lookup
+	}
+	envErr := &EnvError{}
+	fields := configFields(root, "", nil)
+	for _, field := range fields {
+		name := envPrefix + strings.ToUpper(strings.Join(field.names, "__"))
+		raw, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(field.value, raw); err != nil {
+			if isSecretField(field.field) {
+				raw, err = redacted, fmt.Errorf("invalid %s value", field.field.Type)
+			}
+			envErr.Malformed = append(envErr.Malformed, EnvVarError{Name: name, Field: field.path, Value: raw, Err: err})
+			continue
+		}
+		origins[field.path] = OriginEnv
+	}
+	if !envErr.empty() {
+		return nil, nil, envErr
+	}
+
+	// Errors are returned instead of printed, -h and -help give flag.ErrHelp
+	flags := flag.NewFlagSet("config", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	for _, field := range fields {
+		name := strings.ReplaceAll(strings.Join(field.names, "."), "_", "-")
+		flags.Var(&configFlag{field: field, origins: origins}, name, field.field.Tag.Get("description"))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("invalid flags: %w", err)
+	}
+
+	if err := validator.New().Struct(config); err != nil {
+		return nil, nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return &config, origins, nil
+}
+
+// loadConfigFile unmarshals filePath into config and marks the fields it sets, from the keys of the document.
+// Formats without a key tree mark the fields whose value changed.
+func loadConfigFile(filePath string, config any, root reflect.Value, origins map[string]ConfigOrigin) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	detection, err := DetectFormat(filePath, data)
+	if err != nil {
+		return err
+	}
+	parser, err := formatParser(detection.Format)
+	if err != nil {
+		return err
+	}
+
+	before := make(map[string]any)
+	for _, field := range configFields(root, "", nil) {
+		before[field.path] = field.value.Interface()
+	}
+	if err := parser(data, config); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", detection, parseLoadError(data, err))
+	}
+
+	if tree, ok := keyTrees[detection.Format]; ok {
+		if keys, err := tree(data); err == nil {
+			markConfigKeys(keys, root.Type(), "", fieldTags[detection.Format], origins)
+			return nil
+		}
+	}
+	for _, field := range configFields(root, "", nil) {
+		if !reflect.DeepEqual(before[field.path], field.value.Interface()) {
+			origins[field.path] = OriginFile
+		}
+	}
+	return nil
+}
+
+// markConfigKeys marks the fields of typ with a key in node, nested structs are walked like configFields does
+func markConfigKeys(node *keyNode, typ reflect.Type, path, tag string, origins map[string]ConfigOrigin) {
+	if node.Kind != keyMapping {
+		return
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		// Promoted fields are keys of node itself
+		if field.Anonymous && (name == "" || strings.Contains(options, "inline")) && isEnvStruct(field.Type) {
+			markConfigKeys(node, derefType(field.Type), fieldPath, tag, origins)
+			continue
+		}
+
+		name = fieldName(field, tag)
+		for _, child := range node.Fields {
+			if child.Key != name && (tag == "yaml" || !strings.EqualFold(child.Key, name)) {
+				continue
+			}
+			if isEnvStruct(field.Type) {
+				markConfigKeys(child, derefType(field.Type), fieldPath, tag, origins)
+			} else {
+				origins[fieldPath] = OriginFile
+			}
+		}
+	}
+}
+
+// configFields returns the leaf fields of a struct, nested structs are allocated and walked
+func configFields(value reflect.Value, path string, names []string) []configField {
+	var fields []configField
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+		fieldNames := append(append([]string{}, names...), snakeCase(field.Name))
+		fieldValue := value.Field(i)
+
+		if isEnvStruct(field.Type) {
+			if fieldValue.Kind() == reflect.Pointer {
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(field.Type.Elem()))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			fields = append(fields, configFields(fieldValue, fieldPath, fieldNames)...)
+			continue
+		}
+		fields = append(fields, configField{path: fieldPath, names: fieldNames, field: field, value: fieldValue})
+	}
+	return fields
+}
+
+// snakeCase converts a Go name into snake case : MaxConns gives max_conns and DBHost gives db_host
+func snakeCase(name string) string {
+	var builder strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 &&
+			(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			builder.WriteByte('_')
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
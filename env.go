@@ -3,10 +3,49 @@ package simple
 
 import (
 	"os"
+	"io"
+	"bytes"
+	"fmt"
+	"errors"
+	"strings"
+	"io/fs"
 	
 	"github.com/joho/godotenv"
 )
 
+var (
+	ErrEnvFileNotFound = errors.New("env file not found")
+	ErrEnvParse        = errors.New("env parse error")
+	ErrEnvKeyMissing   = errors.New("required key missing")
+)
+
+// EnvParseError reports the file and the line where a .env file couldn't be parsed
+type EnvParseError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *EnvParseError) Error() string {
+	return fmt.Sprintf("%s: %s at line %d: %v", ErrEnvParse, e.File, e.Line, e.Err)
+}
+
+func (e *EnvParseError) Is(target error) bool { return target == ErrEnvParse }
+func (e *EnvParseError) Unwrap() error         { return e.Err }
+
+// envEntry is a single KEY=VALUE assignment of a .env file
+type envEntry struct {
+	Key      string
+	Value    string
+	RawValue string // value as written in the file, with its quotes and escapes
+	Quote    byte   // 0 when unquoted, otherwise ' or "
+	Export   bool
+	Line     int
+	EndLine  int // last line of a multiline value
+	Column   int
+}
+
 
 // Open a .env file
 // Return results in a slice of string
@@ -39,3 +78,206 @@ func OpenEnvFilenames(filenames []string, tags ...string) []string {
 	
 	return tagsSlice
 }
+
+// ReadEnv reads .env files without touching the process environment and returns their values.
+// Later files override earlier ones. Use no filenames to read the default .env file.
+// Set optional to true in order to skip missing files.
+// Every key of `keys` is required : when absent from the files, it is looked up in the process environment,
+// and ErrEnvKeyMissing is returned if it still can't be found. The map then only contains `keys`.
+// Without keys and with optional set to true, the map holds the process environment overridden by the files.
+// The values of the files with a scheme of EnvResolverRegistry are resolved, see ResolveEnv.
+// Values of the process environment are kept as is.
+func ReadEnv(filenames []string, optional bool, keys ...string) (map[string]string, error) {
+	if len(filenames) == 0 {
+		filenames = []string{".env"}
+	}
+
+	values := make(map[string]string)
+	for _, filename := range filenames {
+		entries, err := readEnvFile(filename)
+		if errors.Is(err, ErrEnvFileNotFound) && optional {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			values[entry.Key] = entry.Value
+		}
+	}
+
+	values, err := ResolveEnv(values)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		if optional {
+			for _, variable := range os.Environ() {
+				if key, value, ok := strings.Cut(variable, "="); ok {
+					if _, exists := values[key]; !exists {
+						values[key] = value
+					}
+				}
+			}
+		}
+		return values, nil
+	}
+
+	result := make(map[string]string, len(keys))
+	var missing []string
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			result[key] = value
+		} else if value, ok := os.LookupEnv(key); ok {
+			result[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrEnvKeyMissing, strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
+// readEnvFile opens and parses a single .env file.
+// Encrypted env files are decrypted with the key of EnvKeyFromEnv().
+func readEnvFile(filename string) ([]envEntry, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrEnvFileNotFound, filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open env file: %w", err)
+	}
+
+	if isEncryptedEnv(data) {
+		key, err := EnvKeyFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't decrypt %s: %w", filename, err)
+		}
+		return parseEncryptedEnv(data, filename, key)
+	}
+	return parseEnv(bytes.NewReader(data), filename)
+}
+
+// parseEnv parses the content of a .env file.
+// Supported syntax : comments, `export` prefix, `KEY=VALUE` or `KEY: VALUE`,
+// single quoted (literal) and double quoted (escaped, multiline) values.
+// Variables are not expanded.
+func parseEnv(r io.Reader, filename string) ([]envEntry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read env file: %w", err)
+	}
+	return scanEnv(envLines(string(data)), filename, nil)
+}
+
+// scanEnv parses lines into entries.
+// When errs is nil the first error is returned, otherwise every error is collected and the invalid line is skipped.
+func scanEnv(lines []string, filename string, errs *[]*EnvParseError) ([]envEntry, error) {
+	var entries []envEntry
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		entry := envEntry{Line: i + 1, Column: len(line) - len(strings.TrimLeft(line, " \t")) + 1}
+		fail := func(column int, format string, args ...any) *EnvParseError {
+			return &EnvParseError{File: filename, Line: entry.Line, Column: column, Err: fmt.Errorf(format, args...)}
+		}
+
+		err := func() *EnvParseError {
+			rest := strings.TrimLeft(line, " \t")
+			if strings.HasPrefix(rest, "export ") {
+				entry.Export = true
+				rest = strings.TrimLeft(rest[len("export "):], " \t")
+			}
+			restColumn := len(line) - len(rest) + 1
+
+			sep := strings.IndexAny(rest, "=:")
+			if sep < 0 {
+				return fail(restColumn, "missing '=' separator")
+			}
+			entry.Key = strings.TrimSpace(rest[:sep])
+			if entry.Key == "" || strings.ContainsAny(entry.Key, " \t") {
+				return fail(restColumn, "invalid key %q", entry.Key)
+			}
+
+			value := strings.TrimLeft(rest[sep+1:], " \t")
+			valueColumn := len(line) - len(value) + 1
+			if value == "" || (value[0] != '"' && value[0] != '\'') {
+				// Unquoted values end at an inline comment
+				if idx := strings.Index(value, " #"); idx >= 0 {
+					value = value[:idx]
+				}
+				if idx := strings.Index(value, "\t#"); idx >= 0 {
+					value = value[:idx]
+				}
+				entry.Value = strings.TrimSpace(value)
+				entry.RawValue = entry.Value
+				entry.EndLine = entry.Line
+				return nil
+			}
+
+			entry.Quote = value[0]
+			value = value[1:]
+			end := closingQuote(value, entry.Quote)
+			for end < 0 && i+1 < len(lines) {
+				i++
+				value += "\n" + lines[i]
+				end = closingQuote(value, entry.Quote)
+			}
+			if end < 0 {
+				return fail(valueColumn, "unterminated quoted value")
+			}
+			if remainder := strings.TrimSpace(value[end+1:]); remainder != "" && !strings.HasPrefix(remainder, "#") {
+				return fail(valueColumn, "unexpected characters after quoted value: %q", remainder)
+			}
+
+			entry.Value = value[:end]
+			entry.RawValue = string(entry.Quote) + value[:end+1]
+			entry.EndLine = i + 1
+			if entry.Quote == '"' {
+				entry.Value = unescapeEnvValue(entry.Value)
+			}
+			return nil
+		}()
+
+		if err != nil {
+			if errs == nil {
+				return nil, err
+			}
+			*errs = append(*errs, err)
+			i = entry.Line - 1
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// envLines splits the content of a .env file into lines
+func envLines(data string) []string {
+	return strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+}
+
+// closingQuote returns the index of the quote ending value, or -1
+func closingQuote(value string, quote byte) int {
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && quote == '"':
+			i++
+		case value[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeEnvValue(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return replacer.Replace(value)
+}
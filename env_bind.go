@@ -0,0 +1,246 @@
+package simple
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/joho/godotenv"
+)
+
+// EnvVarError describes a variable whose value couldn't be converted into its field
+type EnvVarError struct {
+	Name  string
+	Field string
+	Value string
+	Err   error
+}
+
+func (e EnvVarError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s=%q: %v", e.Name, e.Value, e.Err)
+	}
+	return fmt.Sprintf("%s=%q (field %s): %v", e.Name, e.Value, e.Field, e.Err)
+}
+
+func (e EnvVarError) Unwrap() error { return e.Err }
+
+// EnvError lists every missing or malformed variable found while binding a struct
+type EnvError struct {
+	Missing   []string
+	Malformed []EnvVarError
+}
+
+func (e *EnvError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("missing variables: %s", strings.Join(e.Missing, ", ")))
+	}
+	if len(e.Malformed) > 0 {
+		var malformed []string
+		for _, m := range e.Malformed {
+			malformed = append(malformed, m.Error())
+		}
+		parts = append(parts, fmt.Sprintf("malformed variables: %s", strings.Join(malformed, "; ")))
+	}
+	return "env: " + strings.Join(parts, "; ")
+}
+
+// Is allows errors.Is(err, ErrEnvKeyMissing) when required variables are missing
+func (e *EnvError) Is(target error) bool {
+	return target == ErrEnvKeyMissing && len(e.Missing) > 0
+}
+
+func (e *EnvError) empty() bool {
+	return len(e.Missing) == 0 && len(e.Malformed) == 0
+}
+
+// LoadEnvInto fills a struct of type `T` from the environment.
+// Fields are bound with the tags `env:"NAME"`, `default:"value"` and `required:"true"`.
+// Values of Secret fields and fields with the `secret:"true"` tag are redacted from errors.
+// Nested structs are walked recursively, their variables are prefixed with the `envPrefix` tag.
+// `prefix` is prepended to every variable name and `filenames` are optional .env files loaded beforehand.
+// Values with a scheme of EnvResolverRegistry, such as `file:/run/secrets/db_password`, are resolved.
+// Set validation to true in order to apply tag validation from validator package
+func LoadEnvInto[T any](prefix string, validation bool, filenames ...string) (*T, error) {
+	if len(filenames) > 0 {
+		if err := godotenv.Load(filenames...); err != nil {
+			return nil, fmt.Errorf("failed to load env files: %w", err)
+		}
+	}
+	return bindEnv[T](os.LookupEnv, prefix, validation, nil)
+}
+
+// bindEnv fills `T` with the values returned by lookup and collects every error into an EnvError.
+// The values with a scheme of EnvResolverRegistry are resolved, unless resolved reports they already are.
+func bindEnv[T any](lookup func(string) (string, bool), prefix string, validation bool, resolved func(string) bool) (*T, error) {
+	var model T
+	value := reflect.ValueOf(&model).Elem()
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("env: %T is not a struct", model)
+	}
+
+	envErr := &EnvError{}
+	bindEnvStruct(value, lookup, prefix, "", resolved, envErr)
+	if !envErr.empty() {
+		return nil, envErr
+	}
+
+	if validation {
+		if err := validator.New().Struct(model); err != nil {
+			return nil, fmt.Errorf("validation failed: %w", err)
+		}
+	}
+	return &model, nil
+}
+
+func bindEnvStruct(value reflect.Value, lookup func(string) (string, bool), prefix, path string, resolved func(string) bool, envErr *EnvError) {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		name, ok := field.Tag.Lookup("env")
+		if !ok {
+			// Nested struct, walk it with the accumulated prefix
+			if isEnvStruct(field.Type) {
+				if fieldValue.Kind() == reflect.Pointer {
+					if fieldValue.IsNil() {
+						fieldValue.Set(reflect.New(field.Type.Elem()))
+					}
+					fieldValue = fieldValue.Elem()
+				}
+				bindEnvStruct(fieldValue, lookup, prefix+field.Tag.Get("envPrefix"), fieldPath, resolved, envErr)
+			}
+			continue
+		}
+		if name == "-" {
+			continue
+		}
+		name = prefix + name
+
+		raw, found := lookup(name)
+		isDefault := false
+		if !found || raw == "" {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				raw, found, isDefault = def, true, true
+			}
+		}
+		if !found {
+			if required, _ := strconv.ParseBool(field.Tag.Get("required")); required {
+				envErr.Missing = append(envErr.Missing, name)
+			}
+			continue
+		}
+
+		if isDefault || resolved == nil || !resolved(name) {
+			value, err := resolveEnvValue(raw)
+			if err != nil {
+				if isSecretField(field) {
+					raw = redacted
+				}
+				envErr.Malformed = append(envErr.Malformed, EnvVarError{Name: name, Field: fieldPath, Value: raw, Err: err})
+				continue
+			}
+			raw = value
+		}
+
+		if err := setEnvValue(fieldValue, raw); err != nil {
+			if isSecretField(field) {
+				raw, err = redacted, fmt.Errorf("invalid %s value", field.Type)
+			}
+			envErr.Malformed = append(envErr.Malformed, EnvVarError{Name: name, Field: fieldPath, Value: raw, Err: err})
+		}
+	}
+}
+
+// isEnvStruct reports whether typ is a struct that should be walked instead of being parsed as a single value
+func isEnvStruct(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+	return !reflect.PointerTo(typ).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// setEnvValue converts raw into the type of value.
+// Slices are read as comma-separated lists.
+func setEnvValue(value reflect.Value, raw string) error {
+	if value.CanAddr() {
+		if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(raw))
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(value.Type().Elem())
+		if err := setEnvValue(elem.Elem(), raw); err != nil {
+			return err
+		}
+		value.Set(elem)
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return err
+			}
+			value.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(raw, 0, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 0, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		if strings.TrimSpace(raw) != "" {
+			parts = strings.Split(raw, ",")
+		}
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setEnvValue(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		value.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type: %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+package simple
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	envCryptHeader = "# simple:encrypted-env v1 salt="
+	envCryptMAC    = " mac="
+	envCryptPrefix = "enc:v1:"
+	envSaltSize    = 16
+)
+
+var ErrEnvTampered = errors.New("env value failed authentication")
+
+// Variables read by EnvKeyFromEnv(), the key file takes precedence over the passphrase
+var (
+	EnvKeyFileVariable    = "SIMPLE_ENV_KEY_FILE"
+	EnvPassphraseVariable = "SIMPLE_ENV_PASSPHRASE"
+)
+
+// EnvKey is the key of an encrypted env file, build it with EnvPassphrase() or EnvKeyFile()
+type EnvKey struct {
+	passphrase []byte
+	key        []byte
+}
+
+// EnvPassphrase derives the key from a passphrase with Argon2id, the salt is stored in the encrypted file
+func EnvPassphrase(passphrase string) EnvKey {
+	return EnvKey{passphrase: []byte(passphrase)}
+}
+
+// EnvKeyFile reads a 32 bytes key from a file, either raw, hex or base64 encoded
+func EnvKeyFile(filePath string) (EnvKey, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return EnvKey{}, fmt.Errorf("couldn't read key file: %w", err)
+	}
+	if len(data) == chacha20poly1305.KeySize {
+		return EnvKey{key: data}, nil
+	}
+
+	text := strings.TrimSpace(string(data))
+	if key, err := hex.DecodeString(text); err == nil && len(key) == chacha20poly1305.KeySize {
+		return EnvKey{key: key}, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(text); err == nil && len(key) == chacha20poly1305.KeySize {
+		return EnvKey{key: key}, nil
+	}
+	return EnvKey{}, fmt.Errorf("invalid key file %s: expected %d bytes", filePath, chacha20poly1305.KeySize)
+}
+
+// EnvKeyFromEnv returns the key of the encrypted env files loaded transparently (e.g. .env.enc),
+// from the key file named by EnvKeyFileVariable or the passphrase of EnvPassphraseVariable
+func EnvKeyFromEnv() (EnvKey, error) {
+	if filePath := os.Getenv(EnvKeyFileVariable); filePath != "" {
+		return EnvKeyFile(filePath)
+	}
+	if passphrase := os.Getenv(EnvPassphraseVariable); passphrase != "" {
+		return EnvPassphrase(passphrase), nil
+	}
+	return EnvKey{}, fmt.Errorf("%w: %s or %s", ErrEnvKeyMissing, EnvKeyFileVariable, EnvPassphraseVariable)
+}
+
+// GenerateEnvKeyFile writes a new random hex encoded key, readable by EnvKeyFile()
+func GenerateEnvKeyFile(filePath string) error {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("couldn't generate key: %w", err)
+	}
+	return os.WriteFile(filePath, []byte(hex.EncodeToString(key)+"\n"), 0600)
+}
+
+// envCipher encrypts the values of one encrypted env file, bound to its salt
+type envCipher struct {
+	aead   cipher.AEAD
+	salt   []byte
+	macKey []byte
+}
+
+// cipher returns the XChaCha20-Poly1305 cipher of the key for the given salt
+func (k EnvKey) cipher(salt []byte) (*envCipher, error) {
+	key := k.key
+	if key == nil {
+		if len(k.passphrase) == 0 {
+			return nil, errors.New("empty env key")
+		}
+		key = argon2.IDKey(k.passphrase, salt, 1, 64*1024, 4, chacha20poly1305.KeySize)
+	}
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	derive := hmac.New(sha256.New, key)
+	derive.Write([]byte("simple:encrypted-env mac"))
+	return &envCipher{aead: aead, salt: salt, macKey: derive.Sum(nil)}, nil
+}
+
+// additionalData binds a value to its key and to the salt of its file
+func (c *envCipher) additionalData(key string) []byte {
+	return append(append([]byte{}, c.salt...), key...)
+}
+
+// seal encrypts the value of key into an enc:v1: token
+func (c *envCipher) seal(key, value string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(value)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("couldn't generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(value), c.additionalData(key))
+	return envCryptPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts an enc:v1: token of key
+func (c *envCipher) open(key, token string) (string, bool) {
+	encoded, ok := strings.CutPrefix(token, envCryptPrefix)
+	if !ok {
+		return "", false
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", false
+	}
+	n := c.aead.NonceSize()
+	plain, err := c.aead.Open(nil, sealed[:n], sealed[n:], c.additionalData(key))
+	return string(plain), err == nil
+}
+
+// mac authenticates the ordered keys and tokens of a file, so deleted, reordered or rolled back entries are detected
+func (c *envCipher) mac(keys, tokens []string) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write(c.salt)
+	for i, key := range keys {
+		mac.Write([]byte(key))
+		mac.Write([]byte{0})
+		mac.Write([]byte(tokens[i]))
+		mac.Write([]byte{0})
+	}
+	return mac.Sum(nil)
+}
+
+// EncryptEnvFile encrypts every value of the .env file `src` into `dst`.
+// Keys, comments and blank lines stay readable. When `dst` already exists, unchanged values keep
+// their ciphertext so diffs only show the keys that actually changed.
+func EncryptEnvFile(src, dst string, key EnvKey) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := envLines(string(data))
+	entries, err := parseEnv(strings.NewReader(string(data)), src)
+	if err != nil {
+		return err
+	}
+
+	// Reuse the salt and the ciphertexts of the previous encryption
+	salt, previous := make([]byte, envSaltSize), make(map[string][2]string)
+	if old, err := os.ReadFile(dst); err == nil {
+		if decrypted, err := decryptEnvEntries(string(old), dst, key); err == nil {
+			salt = decrypted.salt
+			for i, entry := range decrypted.entries {
+				previous[entry.Key] = [2]string{entry.Value, decrypted.plains[i]}
+			}
+		}
+	}
+	if len(previous) == 0 {
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("couldn't generate salt: %w", err)
+		}
+	}
+
+	crypt, err := key.cipher(salt)
+	if err != nil {
+		return err
+	}
+
+	var sealErr error
+	keys, tokens := make([]string, 0, len(entries)), make([]string, 0, len(entries))
+	body := transformEnvLines(lines, entries, func(entry envEntry) string {
+		token, ok := previous[entry.Key]
+		if !ok || token[1] != entry.RawValue {
+			if token[0], err = crypt.seal(entry.Key, entry.RawValue); err != nil {
+				sealErr = err
+				return ""
+			}
+		}
+		keys, tokens = append(keys, entry.Key), append(tokens, token[0])
+		return envAssignment(entry, token[0])
+	})
+
+	if sealErr != nil {
+		return sealErr
+	}
+
+	content := envCryptHeader + base64.StdEncoding.EncodeToString(salt) +
+		envCryptMAC + base64.StdEncoding.EncodeToString(crypt.mac(keys, tokens)) + "\n" + body
+	return writeFileAtomic(dst, []byte(content), 0644)
+}
+
+// DecryptEnvFile decrypts the encrypted env file `src` into the plain .env file `dst`
+func DecryptEnvFile(src, dst string, key EnvKey) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	plain, err := decryptEnvDocument(string(data), src, key)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(dst, []byte(plain), 0600)
+}
+
+// EnvEncryptedFileSource reads an encrypted env file (e.g. .env.enc) into an isolated map, with an explicit key.
+// EnvFileSource and ReadEnv also read encrypted files, with the key of EnvKeyFromEnv().
+// Set optional to true in order to skip the layer when the file doesn't exist.
+func EnvEncryptedFileSource(filename string, optional bool, key EnvKey) EnvSource {
+	source := envEntriesSource(filename, optional, func(filename string) ([]envEntry, error) {
+		data, err := os.ReadFile(filename)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrEnvFileNotFound, filename)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't open env file: %w", err)
+		}
+		return parseEncryptedEnv(data, filename, key)
+	})
+	source.File = filename
+	return source
+}
+
+// isEncryptedEnv reports whether data is the content of an encrypted env file
+func isEncryptedEnv(data []byte) bool {
+	return strings.HasPrefix(string(data), envCryptHeader)
+}
+
+// parseEncryptedEnv decrypts and parses the content of an encrypted env file
+func parseEncryptedEnv(data []byte, filename string, key EnvKey) ([]envEntry, error) {
+	plain, err := decryptEnvDocument(string(data), filename, key)
+	if err != nil {
+		return nil, err
+	}
+	return parseEnv(strings.NewReader(plain), filename)
+}
+
+// decryptEnvDocument returns the plain .env content of an encrypted env file
+func decryptEnvDocument(data, filename string, key EnvKey) (string, error) {
+	decrypted, err := decryptEnvEntries(data, filename, key)
+	if err != nil {
+		return "", err
+	}
+	index := 0
+	return transformEnvLines(envLines(decrypted.body), decrypted.entries, func(entry envEntry) string {
+		index++
+		return envAssignment(entry, decrypted.plains[index-1])
+	}), nil
+}
+
+// decryptedEnv is the content of an encrypted env file, plains holds the raw value of each entry
+type decryptedEnv struct {
+	salt    []byte
+	body    string
+	entries []envEntry
+	plains  []string
+}
+
+// decryptEnvEntries decrypts every entry of an encrypted env file.
+// The list of entries and every value must be authenticated.
+func decryptEnvEntries(data, filename string, key EnvKey) (*decryptedEnv, error) {
+	header, body, _ := strings.Cut(data, "\n")
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(header), envCryptHeader)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an encrypted env file", filename)
+	}
+	encodedSalt, encodedMAC, ok := strings.Cut(encoded, envCryptMAC)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing mac in %s", ErrEnvTampered, filename)
+	}
+	salt, err := base64.StdEncoding.DecodeString(encodedSalt)
+	if err != nil || len(salt) != envSaltSize {
+		return nil, fmt.Errorf("invalid salt in %s", filename)
+	}
+	mac, err := base64.StdEncoding.DecodeString(encodedMAC)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid mac in %s", ErrEnvTampered, filename)
+	}
+
+	crypt, err := key.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseEnv(strings.NewReader(body), filename)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, tokens := make([]string, len(entries)), make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i], tokens[i] = entry.Key, entry.Value
+	}
+	if !hmac.Equal(mac, crypt.mac(keys, tokens)) {
+		return nil, fmt.Errorf("%w: entries of %s were modified", ErrEnvTampered, filename)
+	}
+
+	decrypted := &decryptedEnv{salt: salt, body: body, entries: entries}
+	for _, entry := range entries {
+		plain, ok := crypt.open(entry.Key, entry.Value)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s at line %d of %s", ErrEnvTampered, entry.Key, entry.Line+1, filename)
+		}
+		decrypted.plains = append(decrypted.plains, plain)
+	}
+	return decrypted, nil
+}
+
+// transformEnvLines rewrites every entry with fn while keeping comments and blank lines
+func transformEnvLines(lines []string, entries []envEntry, fn func(envEntry) string) string {
+	var builder strings.Builder
+	next := 0
+	for i := 0; i < len(lines); i++ {
+		if next < len(entries) && entries[next].Line == i+1 {
+			builder.WriteString(fn(entries[next]))
+			i = entries[next].EndLine - 1
+			next++
+		} else {
+			builder.WriteString(lines[i])
+		}
+		if i < len(lines)-1 {
+			builder.WriteByte('\n')
+		}
+	}
+	return builder.String()
+}
+
+// envAssignment formats KEY=value with the export prefix of entry
+func envAssignment(entry envEntry, value string) string {
+	if entry.Export {
+		return "export " + entry.Key + "=" + value
+	}
+	return entry.Key + "=" + value
+}
@@ -0,0 +1,234 @@
+package simple
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// EnvDocument is an editable .env file.
+// Comments, blank lines, quoting style and key order are preserved when it is written back.
+type EnvDocument struct {
+	lines           []envDocumentLine
+	newline         string
+	trailingNewline bool
+}
+
+// envDocumentLine is a comment, a blank line or an assignment (possibly spanning several lines)
+type envDocumentLine struct {
+	text   string
+	entry  *envEntry
+	prefix string // text before the value : indentation, export, key and separator
+	suffix string // text after the value : spaces and inline comment
+}
+
+// ParseEnvDocument reads a .env content into an editable document
+func ParseEnvDocument(r io.Reader) (*EnvDocument, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read env file: %w", err)
+	}
+	return parseEnvDocument(string(data), "")
+}
+
+// OpenEnvDocument reads a .env file into an editable document.
+// A missing file gives an empty document, so keys can be added before calling Save().
+func OpenEnvDocument(filename string) (*EnvDocument, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &EnvDocument{newline: "\n", trailingNewline: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open env file: %w", err)
+	}
+	return parseEnvDocument(string(data), filename)
+}
+
+func parseEnvDocument(data, filename string) (*EnvDocument, error) {
+	entries, err := parseEnv(strings.NewReader(data), filename)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &EnvDocument{newline: "\n", trailingNewline: data == "" || strings.HasSuffix(data, "\n")}
+	if strings.Contains(data, "\r\n") {
+		doc.newline = "\r\n"
+	}
+	lines := envLines(data)
+	if doc.trailingNewline {
+		lines = lines[:len(lines)-1]
+	}
+
+	next := 0
+	for i := 0; i < len(lines); i++ {
+		if next >= len(entries) || entries[next].Line != i+1 {
+			doc.lines = append(doc.lines, envDocumentLine{text: lines[i]})
+			continue
+		}
+
+		entry := entries[next]
+		text := strings.Join(lines[i:entry.EndLine], "\n")
+		start := envValueStart(text)
+		line := envDocumentLine{text: text, entry: &entry, prefix: text[:start]}
+		if rest := text[start:]; strings.HasPrefix(rest, entry.RawValue) {
+			line.suffix = rest[len(entry.RawValue):]
+		}
+		doc.lines = append(doc.lines, line)
+
+		i = entry.EndLine - 1
+		next++
+	}
+	return doc, nil
+}
+
+// envValueStart returns the offset of the value in an assignment
+func envValueStart(text string) int {
+	offset := envKeyStart(text)
+	offset += strings.IndexAny(text[offset:], "=:") + 1
+	return offset + len(text[offset:]) - len(strings.TrimLeft(text[offset:], " \t"))
+}
+
+// envKeyStart returns the offset of the key in the text of an assignment, after any `export` prefix
+func envKeyStart(text string) int {
+	rest := strings.TrimLeft(text, " \t")
+	if strings.HasPrefix(rest, "export ") {
+		rest = strings.TrimLeft(rest[len("export "):], " \t")
+	}
+	return len(text) - len(rest)
+}
+
+// Get returns the value of key, the last assignment wins
+func (d *EnvDocument) Get(key string) (string, bool) {
+	if line := d.last(key); line != nil {
+		return line.entry.Value, true
+	}
+	return "", false
+}
+
+// Keys returns every key in file order, without duplicates
+func (d *EnvDocument) Keys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, line := range d.lines {
+		if line.entry != nil && !seen[line.entry.Key] {
+			seen[line.entry.Key] = true
+			keys = append(keys, line.entry.Key)
+		}
+	}
+	return keys
+}
+
+// Set updates the value of key while keeping its quoting style and inline comment.
+// A new key is appended at the end of the document, it must be a valid variable name.
+func (d *EnvDocument) Set(key, value string) error {
+	if !envKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid key %q", key)
+	}
+
+	line := d.last(key)
+	if line == nil {
+		d.lines = append(d.lines, envDocumentLine{prefix: key + "=", entry: &envEntry{Key: key}})
+		line = &d.lines[len(d.lines)-1]
+	}
+
+	line.entry.Value = value
+	line.entry.RawValue = formatEnvValue(value, line.entry.Quote)
+	line.entry.Quote = 0
+	if raw := line.entry.RawValue; raw != "" && (raw[0] == '"' || raw[0] == '\'') {
+		line.entry.Quote = raw[0]
+	}
+	line.text = line.prefix + line.entry.RawValue + line.suffix
+	return nil
+}
+
+// Delete removes every assignment of key and reports whether it existed
+func (d *EnvDocument) Delete(key string) bool {
+	found := false
+	lines := d.lines[:0]
+	for _, line := range d.lines {
+		if line.entry != nil && line.entry.Key == key {
+			found = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	d.lines = lines
+	return found
+}
+
+// Rename renames every assignment of oldKey, the new key must not exist yet
+func (d *EnvDocument) Rename(oldKey, newKey string) error {
+	if d.last(oldKey) == nil {
+		return fmt.Errorf("%w: %s", ErrEnvKeyMissing, oldKey)
+	}
+	if !envKeyPattern.MatchString(newKey) {
+		return fmt.Errorf("invalid key %q", newKey)
+	}
+	if d.last(newKey) != nil {
+		return fmt.Errorf("key %s already exists", newKey)
+	}
+
+	for i := range d.lines {
+		line := &d.lines[i]
+		if line.entry == nil || line.entry.Key != oldKey {
+			continue
+		}
+		idx := envKeyStart(line.prefix)
+		line.prefix = line.prefix[:idx] + newKey + line.prefix[idx+len(oldKey):]
+		line.entry.Key = newKey
+		line.text = line.prefix + line.entry.RawValue + line.suffix
+	}
+	return nil
+}
+
+// WriteTo writes the document, implements io.WriterTo
+func (d *EnvDocument) WriteTo(w io.Writer) (int64, error) {
+	var builder strings.Builder
+	for i, line := range d.lines {
+		builder.WriteString(strings.ReplaceAll(line.text, "\n", d.newline))
+		if i < len(d.lines)-1 || d.trailingNewline {
+			builder.WriteString(d.newline)
+		}
+	}
+	n, err := io.WriteString(w, builder.String())
+	return int64(n), err
+}
+
+// Save writes the document to filename atomically, through a temporary file renamed over the original.
+// The permissions of an existing file are kept.
+func (d *EnvDocument) Save(filename string) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	var builder strings.Builder
+	if _, err := d.WriteTo(&builder); err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, []byte(builder.String()), perm)
+}
+
+func (d *EnvDocument) last(key string) *envDocumentLine {
+	for i := len(d.lines) - 1; i >= 0; i-- {
+		if d.lines[i].entry != nil && d.lines[i].entry.Key == key {
+			return &d.lines[i]
+		}
+	}
+	return nil
+}
+
+// formatEnvValue writes value with the given quote style, values that can't stay unquoted are double quoted
+func formatEnvValue(value string, quote byte) string {
+	if quote == '\'' && !strings.ContainsAny(value, "'\n") {
+		return "'" + value + "'"
+	}
+	if quote == 0 && !strings.ContainsAny(value, " \t\r\n#\"'\\") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,172 @@
+package simple
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EnvVariable describes a variable bound by a struct field, see LoadEnvInto.
+// Description and Deprecated come from the `description` and `deprecated` tags.
+type EnvVariable struct {
+	Name        string
+	Field       string
+	Default     string
+	HasDefault  bool
+	Required    bool
+	Secret      bool
+	Description string
+	Deprecated  string // deprecation message, empty when the variable isn't deprecated
+}
+
+// EnvDrift lists the differences between a .env file and a config struct
+type EnvDrift struct {
+	Unknown         []string // keys of the file that no field binds
+	Missing         []string // variables absent from the file, including the optional ones
+	MissingRequired []string // required variables without default absent from the file
+	Deprecated      []string // deprecated variables still present in the file
+}
+
+// HasDrift reports whether the file has unknown, deprecated or missing required keys.
+// Optional variables and variables with a default may be absent.
+func (d *EnvDrift) HasDrift() bool {
+	return len(d.Unknown) > 0 || len(d.MissingRequired) > 0 || len(d.Deprecated) > 0
+}
+
+func (d *EnvDrift) String() string {
+	var parts []string
+	if len(d.Unknown) > 0 {
+		parts = append(parts, "unknown: "+strings.Join(d.Unknown, ", "))
+	}
+	if len(d.MissingRequired) > 0 {
+		parts = append(parts, "missing: "+strings.Join(d.MissingRequired, ", "))
+	}
+	if len(d.Deprecated) > 0 {
+		parts = append(parts, "deprecated: "+strings.Join(d.Deprecated, ", "))
+	}
+	if len(parts) == 0 {
+		return "no drift"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// EnvVariables returns every variable bound by the struct `T`, in field order
+func EnvVariables[T any](prefix string) []EnvVariable {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	return envVariables(typ, prefix, "")
+}
+
+func envVariables(typ reflect.Type, prefix, path string) []EnvVariable {
+	var variables []EnvVariable
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		name, ok := field.Tag.Lookup("env")
+		if !ok {
+			if isEnvStruct(field.Type) {
+				nested := field.Type
+				if nested.Kind() == reflect.Pointer {
+					nested = nested.Elem()
+				}
+				variables = append(variables, envVariables(nested, prefix+field.Tag.Get("envPrefix"), fieldPath)...)
+			}
+			continue
+		}
+		if name == "-" {
+			continue
+		}
+
+		variable := EnvVariable{Name: prefix + name, Field: fieldPath, Description: field.Tag.Get("description")}
+		variable.Default, variable.HasDefault = field.Tag.Lookup("default")
+		variable.Required, _ = strconv.ParseBool(field.Tag.Get("required"))
+		variable.Secret = isSecretField(field)
+		if deprecated, ok := field.Tag.Lookup("deprecated"); ok {
+			variable.Deprecated = deprecated
+			if variable.Deprecated == "" || variable.Deprecated == "true" {
+				variable.Deprecated = "deprecated"
+			}
+		}
+		variables = append(variables, variable)
+	}
+	return variables
+}
+
+// EnvExample returns a documented .env.example content for the struct `T`.
+// Deprecated variables are left out and secrets are written without their default.
+func EnvExample[T any](prefix string) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# Generated from %s\n", reflect.TypeFor[T]())
+	for _, variable := range EnvVariables[T](prefix) {
+		if variable.Deprecated != "" {
+			continue
+		}
+
+		builder.WriteString("\n")
+		comment := variable.Description
+		if variable.Required {
+			comment = strings.TrimSpace(comment + " (required)")
+		}
+		if comment != "" {
+			builder.WriteString("# " + comment + "\n")
+		}
+		value := variable.Default
+		if variable.Secret {
+			value = ""
+		}
+		builder.WriteString(variable.Name + "=" + formatEnvValue(value, 0) + "\n")
+	}
+	return builder.String()
+}
+
+// GenerateEnvExample writes the .env.example of the struct `T` into filename, see EnvExample
+func GenerateEnvExample[T any](filename, prefix string) error {
+	return writeFileAtomic(filename, []byte(EnvExample[T](prefix)), 0644)
+}
+
+// CheckEnvDrift compares the keys of a .env file with the variables of the struct `T`
+func CheckEnvDrift[T any](filename, prefix string) (*EnvDrift, error) {
+	entries, err := readEnvFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		present[entry.Key] = true
+	}
+
+	drift := &EnvDrift{}
+	declared := make(map[string]bool)
+	for _, variable := range EnvVariables[T](prefix) {
+		declared[variable.Name] = true
+		switch {
+		case variable.Deprecated != "":
+			if present[variable.Name] {
+				drift.Deprecated = append(drift.Deprecated, variable.Name)
+			}
+		case !present[variable.Name]:
+			drift.Missing = append(drift.Missing, variable.Name)
+			if variable.Required && !variable.HasDefault {
+				drift.MissingRequired = append(drift.MissingRequired, variable.Name)
+			}
+		}
+	}
+	for key := range present {
+		if !declared[key] {
+			drift.Unknown = append(drift.Unknown, key)
+		}
+	}
+	slices.Sort(drift.Unknown)
+	return drift, nil
+}
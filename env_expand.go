@@ -0,0 +1,180 @@
+package simple
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrEnvCycle     = errors.New("env reference cycle")
+	ErrEnvUndefined = errors.New("env variable undefined")
+)
+
+// envExpander resolves references between values, each key is resolved once
+type envExpander struct {
+	values   map[string]string
+	raw      map[string]bool
+	resolved map[string]string
+	stack    []string
+}
+
+// ExpandEnv resolves the references of every value and returns a new map.
+// Supported syntax : ${VAR}, ${VAR:-default}, ${VAR:?error message} and $$ for a literal $.
+// References are looked up in values first, then in the process environment.
+func ExpandEnv(values map[string]string) (map[string]string, error) {
+	return newEnvExpander(values, nil).expandAll()
+}
+
+// Expand resolves the references of every value once all the layers are merged, see ExpandEnv.
+// Single quoted values and values coming from the process environment are kept as is.
+func (e *Env) Expand() error {
+	expanded, err := newEnvExpander(e.values, e.raw).expandAll()
+	if err != nil {
+		return err
+	}
+	e.values = expanded
+	return nil
+}
+
+func newEnvExpander(values map[string]string, raw map[string]bool) *envExpander {
+	return &envExpander{values: values, raw: raw, resolved: make(map[string]string)}
+}
+
+func (x *envExpander) expandAll() (map[string]string, error) {
+	result := make(map[string]string, len(x.values))
+	for key := range x.values {
+		value, err := x.resolve(key)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// lookup returns the expanded value of key and whether it is defined
+func (x *envExpander) lookup(key string) (string, bool, error) {
+	if _, ok := x.values[key]; ok {
+		value, err := x.resolve(key)
+		return value, true, err
+	}
+	value, ok := os.LookupEnv(key)
+	return value, ok, nil
+}
+
+func (x *envExpander) resolve(key string) (string, error) {
+	if value, ok := x.resolved[key]; ok {
+		return value, nil
+	}
+	if x.raw[key] {
+		return x.values[key], nil
+	}
+
+	for i, visiting := range x.stack {
+		if visiting == key {
+			chain := append(slices.Clone(x.stack[i:]), key)
+			return "", fmt.Errorf("%w: %s", ErrEnvCycle, strings.Join(chain, " -> "))
+		}
+	}
+
+	x.stack = append(x.stack, key)
+	value, err := x.expand(key, x.values[key])
+	x.stack = x.stack[:len(x.stack)-1]
+	if err != nil {
+		return "", err
+	}
+
+	x.resolved[key] = value
+	return value, nil
+}
+
+// expand replaces every reference of value, key is the variable owning value
+func (x *envExpander) expand(key, value string) (string, error) {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			builder.WriteByte(value[i])
+			continue
+		}
+
+		switch value[i+1] {
+		case '$':
+			builder.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(value, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("%s: unterminated reference in %q", key, value)
+			}
+			expanded, err := x.reference(key, value[i+2:end])
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(expanded)
+			i = end
+		default:
+			builder.WriteByte('$')
+		}
+	}
+	return builder.String(), nil
+}
+
+// reference expands the content of ${...}
+func (x *envExpander) reference(key, expression string) (string, error) {
+	name, operand, operator := expression, "", ""
+	if idx := strings.Index(expression, ":"); idx >= 0 && idx+1 < len(expression) {
+		name, operator, operand = expression[:idx], expression[idx:idx+2], expression[idx+2:]
+	}
+	if name == "" {
+		return "", fmt.Errorf("%s: empty reference", key)
+	}
+
+	value, ok, err := x.lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	switch operator {
+	case "":
+		return value, nil
+	case ":-":
+		if ok && value != "" {
+			return value, nil
+		}
+		return x.expand(key, operand)
+	case ":?":
+		if ok && value != "" {
+			return value, nil
+		}
+		message, err := x.expand(key, operand)
+		if err != nil {
+			return "", err
+		}
+		if message == "" {
+			message = "required"
+		}
+		return "", fmt.Errorf("%w: %s referenced by %s: %s", ErrEnvUndefined, name, key, message)
+	default:
+		return "", fmt.Errorf("%s: unsupported operator %q in ${%s}", key, operator, expression)
+	}
+}
+
+// closingBrace returns the index of the brace closing a reference starting at start, or -1
+func closingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,174 @@
+package simple
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// envGet parses the value of key, errors name the variable and its raw value
+func envGet[V any](e *Env, key string, parse func(string) (V, error)) (V, error) {
+	var zero V
+	raw, ok := e.Lookup(key)
+	if !ok {
+		return zero, fmt.Errorf("%w: %s", ErrEnvKeyMissing, key)
+	}
+	value, err := parse(raw)
+	if err != nil {
+		return zero, EnvVarError{Name: key, Value: raw, Err: err}
+	}
+	return value, nil
+}
+
+// Int returns the value of key as an int
+func (e *Env) Int(key string) (int, error) {
+	return envGet(e, key, strconv.Atoi)
+}
+
+// Bool returns the value of key as a bool
+func (e *Env) Bool(key string) (bool, error) {
+	return envGet(e, key, strconv.ParseBool)
+}
+
+// Duration returns the value of key as a duration, such as `1m30s`
+func (e *Env) Duration(key string) (time.Duration, error) {
+	return envGet(e, key, time.ParseDuration)
+}
+
+// ByteSize returns the value of key as a number of bytes, such as `512MiB` or `1.5GB`.
+// Units : B, KB, MB, GB, TB (powers of 1000) and KiB, MiB, GiB, TiB (powers of 1024).
+func (e *Env) ByteSize(key string) (int64, error) {
+	return envGet(e, key, parseByteSize)
+}
+
+// List returns the comma-separated value of key, elements are trimmed and empty ones dropped
+func (e *Env) List(key string) ([]string, error) {
+	return envGet(e, key, func(raw string) ([]string, error) {
+		return splitEnvList(raw), nil
+	})
+}
+
+// StringMap returns the value of key as a map, such as `region=eu,tier=gold`
+func (e *Env) StringMap(key string) (map[string]string, error) {
+	return envGet(e, key, func(raw string) (map[string]string, error) {
+		values := make(map[string]string)
+		for _, pair := range splitEnvList(raw) {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return nil, fmt.Errorf("invalid pair %q, expected k=v", pair)
+			}
+			values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		return values, nil
+	})
+}
+
+// URL returns the value of key as an absolute URL
+func (e *Env) URL(key string) (*url.URL, error) {
+	return envGet(e, key, func(raw string) (*url.URL, error) {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme == "" {
+			return nil, errors.New("missing URL scheme")
+		}
+		return u, nil
+	})
+}
+
+// IP returns the value of key as an IPv4 or IPv6 address
+func (e *Env) IP(key string) (net.IP, error) {
+	return envGet(e, key, func(raw string) (net.IP, error) {
+		ip := net.ParseIP(raw)
+		if ip == nil {
+			return nil, errors.New("invalid IP address")
+		}
+		return ip, nil
+	})
+}
+
+// CIDR returns the value of key as a network, such as `10.0.0.0/8`
+func (e *Env) CIDR(key string) (*net.IPNet, error) {
+	return envGet(e, key, func(raw string) (*net.IPNet, error) {
+		_, network, err := net.ParseCIDR(raw)
+		return network, err
+	})
+}
+
+// Location returns the value of key as a time zone, such as `Europe/Paris`
+func (e *Env) Location(key string) (*time.Location, error) {
+	return envGet(e, key, time.LoadLocation)
+}
+
+// Enum returns the value of key when it is one of allowed
+func (e *Env) Enum(key string, allowed ...string) (string, error) {
+	return envGet(e, key, func(raw string) (string, error) {
+		if !slices.Contains(allowed, raw) {
+			return "", fmt.Errorf("expected one of %s", strings.Join(allowed, ", "))
+		}
+		return raw, nil
+	})
+}
+
+func splitEnvList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func parseByteSize(raw string) (int64, error) {
+	text := strings.TrimSpace(raw)
+	split := strings.IndexFunc(text, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if split < 0 {
+		split = len(text)
+	}
+
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(text[split:]))]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", strings.TrimSpace(text[split:]))
+	}
+
+	// Whole numbers are exact, floats lose precision above 2^53
+	if whole, err := strconv.ParseInt(text[:split], 10, 64); err == nil && whole >= 0 {
+		if whole > math.MaxInt64/int64(unit) {
+			return 0, fmt.Errorf("size %q overflows int64", raw)
+		}
+		return whole * int64(unit), nil
+	}
+
+	number, err := strconv.ParseFloat(text[:split], 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q", raw)
+	}
+	size := number * unit
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q overflows int64", raw)
+	}
+	return int64(size), nil
+}
@@ -0,0 +1,124 @@
+package simple
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// LintSeverity is the importance of a diagnostic
+type LintSeverity int
+
+const (
+	LintInfo LintSeverity = iota
+	LintWarning
+	LintError
+)
+
+func (s LintSeverity) String() string {
+	switch s {
+	case LintInfo:
+		return "info"
+	case LintWarning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// EnvDiagnostic is an issue found in a .env file, Line and Column start at 1
+type EnvDiagnostic struct {
+	File     string
+	Line     int
+	Column   int
+	Severity LintSeverity
+	Rule     string
+	Message  string
+}
+
+func (d EnvDiagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", d.File, d.Line, d.Column, d.Severity, d.Message, d.Rule)
+}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LintEnvFiles checks .env files before a deploy, in the order given to OpenEnvFilenames().
+// Rules : parse errors (such as unbalanced quotes), invalid key names, duplicate keys within
+// and across files, trailing whitespace in unquoted values, unquoted `#` kept in a value
+// and keys shadowing a variable of the process environment.
+// The error is only set when a file can't be read.
+func LintEnvFiles(filenames ...string) ([]EnvDiagnostic, error) {
+	var diagnostics []EnvDiagnostic
+	type position struct {
+		file string
+		line int
+	}
+	seen := make(map[string]position)
+
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read env file: %w", err)
+		}
+		lines := envLines(string(data))
+
+		var parseErrors []*EnvParseError
+		entries, _ := scanEnv(lines, filename, &parseErrors)
+		for _, parseErr := range parseErrors {
+			diagnostics = append(diagnostics, EnvDiagnostic{
+				File: filename, Line: parseErr.Line, Column: parseErr.Column,
+				Severity: LintError, Rule: "syntax", Message: parseErr.Err.Error(),
+			})
+		}
+
+		for _, entry := range entries {
+			at := func(column int, severity LintSeverity, rule, format string, args ...any) EnvDiagnostic {
+				return EnvDiagnostic{File: filename, Line: entry.Line, Column: column, Severity: severity, Rule: rule, Message: fmt.Sprintf(format, args...)}
+			}
+			line := lines[entry.Line-1]
+			valueColumn := envValueStart(line) + 1
+
+			if !envKeyPattern.MatchString(entry.Key) {
+				diagnostics = append(diagnostics, at(entry.Column, LintError, "key-name", "invalid key name %q", entry.Key))
+			}
+
+			if previous, ok := seen[entry.Key]; ok {
+				where := fmt.Sprintf("line %d", previous.line)
+				if previous.file != filename {
+					where = fmt.Sprintf("%s:%d", previous.file, previous.line)
+				}
+				diagnostics = append(diagnostics, at(entry.Column, LintWarning, "duplicate-key", "%s is already defined at %s", entry.Key, where))
+			} else {
+				seen[entry.Key] = position{file: filename, line: entry.Line}
+			}
+
+			if entry.Quote == 0 {
+				end := valueColumn - 1 + len(entry.RawValue)
+				if suffix := line[end:]; suffix != "" && strings.TrimSpace(suffix) == "" {
+					diagnostics = append(diagnostics, at(end+1, LintWarning, "trailing-whitespace", "trailing whitespace after the value of %s", entry.Key))
+				}
+				if idx := strings.Index(entry.Value, "#"); idx >= 0 {
+					diagnostics = append(diagnostics, at(valueColumn+idx, LintWarning, "unquoted-hash", "unquoted # is kept in the value of %s, quote the value to make it explicit", entry.Key))
+				}
+			}
+
+			if _, ok := os.LookupEnv(entry.Key); ok {
+				diagnostics = append(diagnostics, at(entry.Column, LintInfo, "shadow-os", "%s shadows a variable of the process environment", entry.Key))
+			}
+		}
+	}
+	return diagnostics, nil
+}
+
+// LintErrors returns an error listing the diagnostics with at least the given severity, or nil
+func LintErrors(diagnostics []EnvDiagnostic, severity LintSeverity) error {
+	var errs []error
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity >= severity {
+			errs = append(errs, errors.New(diagnostic.String()))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+package simple
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvResolver turns a reference (the value without its scheme prefix) into the real value
+type EnvResolver func(reference string) (string, error)
+
+// Values starting with a registered scheme, such as `file:/run/secrets/db_password`, are resolved by Resolve()
+// and by the loaders ReadEnv, LoadEnvInto, LoadEnvProfile and BindEnv. The process environment is only resolved
+// for the variables bound to a struct.
+// Add your own resolver with EnvResolverRegistry["vault"] = myResolver
+var EnvResolverRegistry = map[string]EnvResolver{
+	"file":   resolveEnvFile,
+	"base64": resolveEnvBase64,
+	"env":    resolveEnvVariable,
+}
+
+// ResolveEnv resolves every value with a registered scheme prefix and returns a new map
+func ResolveEnv(values map[string]string) (map[string]string, error) {
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		resolved, err := resolveEnvValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't resolve %s: %w", key, err)
+		}
+		result[key] = resolved
+	}
+	return result, nil
+}
+
+// Resolve resolves every value with a registered scheme prefix, see ResolveEnv.
+// Values of the process environment (EnvOSSource) are kept as is, they may use a scheme for another purpose
+// such as SQLITE_URL=file:app.db. Call it after Expand() so references can be built from other variables.
+// Values are resolved once, later calls do nothing.
+func (e *Env) Resolve() error {
+	if e.resolved {
+		return nil
+	}
+	resolved := make(map[string]string, len(e.values))
+	for key, value := range e.values {
+		if e.origins[key] == envOSSourceName {
+			resolved[key] = value
+			continue
+		}
+		value, err := resolveEnvValue(value)
+		if err != nil {
+			return fmt.Errorf("couldn't resolve %s: %w", key, err)
+		}
+		resolved[key] = value
+	}
+	e.values = resolved
+	e.resolved = true
+	return nil
+}
+
+func resolveEnvValue(value string) (string, error) {
+	scheme, reference, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+	resolver, ok := EnvResolverRegistry[strings.ToLower(scheme)]
+	if !ok {
+		return value, nil
+	}
+	return resolver(reference)
+}
+
+// resolveEnvFile reads the content of a file, without its trailing newline
+func resolveEnvFile(reference string) (string, error) {
+	data, err := os.ReadFile(reference)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func resolveEnvBase64(reference string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(reference)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 value: %w", err)
+	}
+	return string(data), nil
+}
+
+// resolveEnvVariable reads another variable of the process environment
+func resolveEnvVariable(reference string) (string, error) {
+	value, ok := os.LookupEnv(reference)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrEnvUndefined, reference)
+	}
+	return value, nil
+}
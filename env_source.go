@@ -0,0 +1,209 @@
+package simple
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// EnvSource is a layer of environment values.
+// Load must not modify the process environment, it returns a nil map when the layer is skipped.
+type EnvSource struct {
+	Name string
+	File string // path of the file read by the source, if any
+	Load func() (map[string]string, error)
+	Raw  func(key string) bool // reports whether a loaded value must be kept as is by Expand, nil means never
+}
+
+// Env holds the merged values of several EnvSource and remembers the layer each value came from
+type Env struct {
+	values   map[string]string
+	origins  map[string]string
+	raw      map[string]bool
+	resolved bool // Resolve was called
+	sources  []string
+	files    []string
+}
+
+// EnvFileSource reads a .env file into an isolated map.
+// Set optional to true in order to skip the layer when the file doesn't exist.
+// Single quoted values are kept as is by Expand.
+func EnvFileSource(filename string, optional bool) EnvSource {
+	source := envEntriesSource(filename, optional, readEnvFile)
+	source.File = filename
+	return source
+}
+
+// envEntriesSource builds a file source from a function reading the entries of filename
+func envEntriesSource(filename string, optional bool, read func(string) ([]envEntry, error)) EnvSource {
+	quoted := make(map[string]bool)
+	return EnvSource{
+		Name: filename,
+		Raw:  func(key string) bool { return quoted[key] },
+		Load: func() (map[string]string, error) {
+			entries, err := read(filename)
+			if errors.Is(err, ErrEnvFileNotFound) && optional {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			clear(quoted)
+			values := make(map[string]string, len(entries))
+			for _, entry := range entries {
+				values[entry.Key] = entry.Value
+				quoted[entry.Key] = entry.Quote == '\''
+			}
+			return values, nil
+		},
+	}
+}
+
+// EnvStringSource parses an in-memory .env content, nothing is read from the disk
+func EnvStringSource(name, content string) EnvSource {
+	return envEntriesSource(name, false, func(name string) ([]envEntry, error) {
+		return parseEnv(strings.NewReader(content), name)
+	})
+}
+
+// envOSSourceName is the name and the origin of the values of EnvOSSource
+const envOSSourceName = "os"
+
+// EnvOSSource reads the real process environment, its values are kept as is by Expand
+func EnvOSSource() EnvSource {
+	return EnvSource{
+		Name: envOSSourceName,
+		Raw:  func(string) bool { return true },
+		Load: func() (map[string]string, error) {
+			values := make(map[string]string)
+			for _, variable := range os.Environ() {
+				if key, value, ok := strings.Cut(variable, "="); ok {
+					values[key] = value
+				}
+			}
+			return values, nil
+		},
+	}
+}
+
+// EnvMapSource uses the given values, for defaults or explicit overrides
+func EnvMapSource(name string, values map[string]string) EnvSource {
+	return EnvSource{
+		Name: name,
+		Load: func() (map[string]string, error) {
+			return maps.Clone(values), nil
+		},
+	}
+}
+
+// LoadEnvSources merges sources in the given order, later sources override earlier ones.
+// A typical order is : defaults, .env, .env.local, EnvOSSource() and explicit overrides.
+func LoadEnvSources(sources ...EnvSource) (*Env, error) {
+	env := &Env{values: make(map[string]string), origins: make(map[string]string), raw: make(map[string]bool)}
+	for _, source := range sources {
+		values, err := source.Load()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load env source %s: %w", source.Name, err)
+		}
+		if values == nil {
+			continue
+		}
+		env.sources = append(env.sources, source.Name)
+		if source.File != "" {
+			env.files = append(env.files, source.File)
+		}
+		for key, value := range values {
+			env.values[key] = value
+			env.origins[key] = source.Name
+			env.raw[key] = source.Raw != nil && source.Raw(key)
+		}
+	}
+	return env, nil
+}
+
+// EnvProfileSources returns the files of a profile in their loading order :
+// .env, .env.enc, .env.<profile>, .env.<profile>.enc, .env.local and .env.<profile>.local. Missing files are skipped.
+// The encrypted .enc files are decrypted with the key of EnvKeyFromEnv().
+func EnvProfileSources(dir, profile string) []EnvSource {
+	names := []string{".env", ".env.enc"}
+	if profile != "" {
+		names = append(names, ".env."+profile, ".env."+profile+".enc")
+	}
+	names = append(names, ".env.local")
+	if profile != "" {
+		names = append(names, ".env."+profile+".local")
+	}
+
+	var sources []EnvSource
+	for _, name := range names {
+		sources = append(sources, EnvFileSource(filepath.Join(dir, name), true))
+	}
+	return sources
+}
+
+// LoadEnvProfile loads the profile files of dir, the profile is read from the `selector` variable (e.g. APP_ENV)
+// and defaults to "development". The process environment is applied last and overrides every file.
+// The values of the files are resolved, see Resolve. Use LoadEnvSources to expand them beforehand.
+// Use Sources() to know which files were actually applied.
+func LoadEnvProfile(dir, selector string) (*Env, error) {
+	profile := os.Getenv(selector)
+	if profile == "" {
+		profile = "development"
+	}
+	env, err := LoadEnvSources(append(EnvProfileSources(dir, profile), EnvOSSource())...)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.Resolve(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// BindEnv fills a struct of type `T` from env, see LoadEnvInto for the supported tags.
+// The bound values are resolved, unless env.Resolve() already did.
+func BindEnv[T any](env *Env, prefix string, validation bool) (*T, error) {
+	return bindEnv[T](env.Lookup, prefix, validation, func(key string) bool {
+		return env.resolved && env.origins[key] != envOSSourceName
+	})
+}
+
+// Lookup returns the value of key and whether it exists
+func (e *Env) Lookup(key string) (string, bool) {
+	value, ok := e.values[key]
+	return value, ok
+}
+
+// Get returns the value of key or an empty string
+func (e *Env) Get(key string) string {
+	return e.values[key]
+}
+
+// Origin returns the name of the source the value of key came from
+func (e *Env) Origin(key string) string {
+	return e.origins[key]
+}
+
+// Keys returns every key, sorted
+func (e *Env) Keys() []string {
+	return slices.Sorted(maps.Keys(e.values))
+}
+
+// Sources returns the name of every applied source, in loading order
+func (e *Env) Sources() []string {
+	return slices.Clone(e.sources)
+}
+
+// Files returns the applied sources that are files, in loading order
+func (e *Env) Files() []string {
+	return slices.Clone(e.files)
+}
+
+// Map returns a copy of every value
+func (e *Env) Map() map[string]string {
+	return maps.Clone(e.values)
+}
@@ -0,0 +1,154 @@
+package simple
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EnvWatcher polls the files of its sources and reloads them on change
+type EnvWatcher struct {
+	sources  []EnvSource
+	interval time.Duration
+	debounce time.Duration
+
+	mu        sync.Mutex
+	env       *Env
+	stamps    map[string]envFileStamp
+	callbacks []func(env *Env, changed []string)
+	onError   func(error)
+}
+
+// envFileStamp identifies a version of a file
+type envFileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// NewEnvWatcher loads sources and returns a watcher checking their files every `interval`.
+// Once a change is seen, the watcher waits for `debounce` without further change before reloading.
+// interval must be positive and debounce can't be negative.
+func NewEnvWatcher(interval, debounce time.Duration, sources ...EnvSource) (*EnvWatcher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("env watcher: interval must be positive, got %s", interval)
+	}
+	if debounce < 0 {
+		return nil, fmt.Errorf("env watcher: debounce can't be negative, got %s", debounce)
+	}
+
+	env, err := LoadEnvSources(sources...)
+	if err != nil {
+		return nil, err
+	}
+	w := &EnvWatcher{sources: sources, interval: interval, debounce: debounce, env: env}
+	w.stamps = w.stat()
+	return w, nil
+}
+
+// OnChange registers a callback called after a reload with the new values and the sorted changed keys
+func (w *EnvWatcher) OnChange(callback func(env *Env, changed []string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callbacks = append(w.callbacks, callback)
+}
+
+// OnError registers a callback called when a reload fails, the previous values are kept
+func (w *EnvWatcher) OnError(callback func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = callback
+}
+
+// Env returns the last successfully loaded values
+func (w *EnvWatcher) Env() *Env {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.env
+}
+
+// Run polls the files until ctx is done
+func (w *EnvWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	var pendingSince time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if stamps := w.stat(); !maps.Equal(stamps, w.stamps) {
+				w.stamps = stamps
+				pendingSince = now
+			}
+			if !pendingSince.IsZero() && now.Sub(pendingSince) >= w.debounce {
+				pendingSince = time.Time{}
+				w.reload()
+			}
+		}
+	}
+}
+
+func (w *EnvWatcher) reload() {
+	env, err := LoadEnvSources(w.sources...)
+
+	w.mu.Lock()
+	if err != nil {
+		onError := w.onError
+		w.mu.Unlock()
+		if onError != nil {
+			onError(err)
+		}
+		return
+	}
+	previous := w.env
+	w.env = env
+	callbacks := slices.Clone(w.callbacks)
+	w.mu.Unlock()
+
+	changed := diffEnv(previous, env)
+	if len(changed) == 0 {
+		return
+	}
+	for _, callback := range callbacks {
+		callback(env, changed)
+	}
+}
+
+// stat returns the current stamp of every watched file
+func (w *EnvWatcher) stat() map[string]envFileStamp {
+	stamps := make(map[string]envFileStamp)
+	for _, source := range w.sources {
+		if source.File == "" {
+			continue
+		}
+		var stamp envFileStamp
+		if info, err := os.Stat(source.File); err == nil {
+			stamp = envFileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+		}
+		stamps[source.File] = stamp
+	}
+	return stamps
+}
+
+// diffEnv returns the sorted keys added, removed or modified between two loads
+func diffEnv(previous, current *Env) []string {
+	var changed []string
+	for key, value := range current.values {
+		if old, ok := previous.values[key]; !ok || old != value {
+			changed = append(changed, key)
+		}
+	}
+	for key := range previous.values {
+		if _, ok := current.values[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -4,8 +4,13 @@ import (
 	"io"
 	"os"
 	"fmt"
-	"path"
+	"iter"
+	"errors"
+	"io/fs"
+	"bufio"
+	"bytes"
 	"context"
+	"reflect"
 	"strings"
 	"archive/zip"
 	"encoding/xml"
@@ -20,40 +25,111 @@ import (
 type FileParser func([]byte, any) error
 
 var FileRegistry = map[string]FileParser{
-	"json": json.Unmarshal,
-	"yaml": yaml.Unmarshal,
-	"toml": toml.Unmarshal,
-	"xml":  xml.Unmarshal,
+	"json":   json.Unmarshal,
+	"yaml":   yaml.Unmarshal,
+	"toml":   toml.Unmarshal,
+	"xml":    xml.Unmarshal,
+	"csv":    csvFormat.Unmarshal,
+	"tsv":    tsvFormat.Unmarshal,
+	"jsonl":  jsonLinesFormat.Unmarshal,
+	"ndjson": jsonLinesFormat.Unmarshal,
 }
 
-// Deserialize data from the given data type (json, yaml, toml or xml)
+// fileCollections split the documents holding their elements under a root element, such as an xml <items>,
+// into the span of each element. They report false for a document parsed as a whole.
+var fileCollections = map[string]func(data []byte, elem reflect.Type) ([]dataSpan, bool){
+	"xml": xmlCollection,
+}
+
+// dataSpan is the byte range [start, end) of an element in the data
+type dataSpan struct {
+	start, end int64
+}
+
+// fileLocators add the position of an invalid element to its validation error
+var fileLocators = map[string]func(data []byte, index int, elem any, err error) error{
+	"csv":    csvFormat.locate,
+	"tsv":    tsvFormat.locate,
+	"jsonl":  jsonLinesFormat.locate,
+	"ndjson": jsonLinesFormat.locate,
+}
+
+// Deserialize data from the given data type (json, yaml, toml, xml, csv, tsv, jsonl or ndjson).
+// Compressed files such as records.json.gz are decompressed with DecompressorRegistry.
 func LoadFile[S any](filePath string, limit int, validation bool) ([]S, error) {
-	data, err := os.ReadFile(filePath)
+	return loadFileWith[S](FileRegistry, filePath, os.Open, limit, validation)
+}
+
+// LoadFileAs is LoadFile with a format forced by the caller, such as json or yml, instead of its detection
+func LoadFileAs[S any](filePath string, format string, limit int, validation bool) ([]S, error) {
+	data, _, err := readFile(filePath, os.Open)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, err
 	}
+	return loadData[S](data, forcedFormat(format), limit, validation)
+}
 
-	ext := strings.TrimPrefix(strings.ToLower(path.Ext(filePath)), ".")
-	parser, ok := FileRegistry[ext]
-	if !ok {
-		return nil, fmt.Errorf("unsupported file format: %s", ext)
+// LoadFS is LoadFile reading name from fsys, such as an embed.FS or a fstest.MapFS
+func LoadFS[S any](fsys fs.FS, name string, limit int, validation bool) ([]S, error) {
+	return loadFileWith[S](FileRegistry, name, fsys.Open, limit, validation)
+}
+
+// LoadReader is LoadFile reading r until EOF, such as an HTTP request body.
+// format is a key of FileRegistry or FormatAliases, such as json or .yml, with an optional compression such as json.gz.
+// Use an empty format to detect it from the content.
+func LoadReader[S any](r io.Reader, format string, limit int, validation bool) ([]S, error) {
+	return loadReaderWith[S](FileRegistry, r, format, limit, validation)
+}
+
+// loadFileWith reads the file opened by open and parses it with the parsers of registry
+func loadFileWith[S any, F io.ReadCloser](registry map[string]FileParser, name string, open func(string) (F, error), limit int, validation bool) ([]S, error) {
+	data, name, err := readFile(name, open)
+	if err != nil {
+		return nil, err
 	}
+	detection, err := DetectFormat(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return loadDataWith[S](registry, data, detection, limit, validation)
+}
 
-	var items []S
-	if err := parser(data, &items); err != nil {
-		var single S
-		if err2 := parser(data, &single); err2 != nil {
-			return nil, fmt.Errorf("failed to parse %s: %w", ext, err)
+// loadReaderWith reads r and parses it with the parsers of registry, see LoadReader
+func loadReaderWith[S any](registry map[string]FileParser, r io.Reader, format string, limit int, validation bool) ([]S, error) {
+	data, name, err := readData("."+strings.TrimPrefix(format, "."), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data: %w", err)
+	}
+
+	detection := forcedFormat(name)
+	if name == "." {
+		if detection, err = DetectFormat("", data); err != nil {
+			return nil, err
 		}
-		items = append(items, single)
+	}
+	return loadDataWith[S](registry, data, detection, limit, validation)
+}
+
+// loadData parses data with the parser of FileRegistry for the detected format, then applies the validation and the limit
+func loadData[S any](data []byte, detection FormatDetection, limit int, validation bool) ([]S, error) {
+	return loadDataWith[S](FileRegistry, data, detection, limit, validation)
+}
+
+// loadDataWith is loadData with the parsers of registry
+func loadDataWith[S any](registry map[string]FileParser, data []byte, detection FormatDetection, limit int, validation bool) ([]S, error) {
+	parser, ok := registry[detection.Format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file format: %s", detection.Format)
+	}
+
+	items, single, err := parseItems[S](parser, data, detection.Format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", detection, err)
 	}
 
 	if validation {
-		validate := validator.New()
-		for i, elem := range items {
-			if err := validate.Struct(elem); err != nil {
-				return nil, fmt.Errorf("validation failed for element %d: %w", i, err)
-			}
+		if errs := validationLoadErrors(data, detection.Format, items, single); len(errs) > 0 {
+			return nil, fmt.Errorf("validation failed: %w", errs)
 		}
 	}
 
@@ -64,6 +140,358 @@ func LoadFile[S any](filePath string, limit int, validation bool) ([]S, error) {
 	return items, nil
 }
 
+// parseItems parses data as a slice, or as a single element when it isn't one.
+// The elements of a collection, see fileCollections, are parsed one at a time.
+func parseItems[S any](parser FileParser, data []byte, format string) ([]S, bool, error) {
+	if split, ok := fileCollections[format]; ok {
+		if spans, ok := split(data, reflect.TypeFor[S]()); ok {
+			items := make([]S, len(spans))
+			for i, span := range spans {
+				if err := parser(data[span.start:span.end], &items[i]); err != nil {
+					return nil, false, spanLoadErrors(data, span, i, err)
+				}
+			}
+			return items, false, nil
+		}
+	}
+
+	var items []S
+	if err := parser(data, &items); err != nil {
+		var one S
+		if err2 := parser(data, &one); err2 != nil {
+			// strict errors of a single element are more relevant than the failure of the slice
+			var located LoadErrors
+			if errors.As(err2, &located) {
+				return nil, false, err2
+			}
+			if !errors.As(err, &located) {
+				err = parseLoadError(data, err)
+			}
+			return nil, false, err
+		}
+		return []S{one}, true, nil
+	}
+	return items, false, nil
+}
+
+// readFile reads the file opened by open, decompressed according to its extension.
+// It returns the name of the decompressed data.
+func readFile[F io.ReadCloser](name string, open func(string) (F, error)) ([]byte, string, error) {
+	file, err := open(name)
+	if err != nil {
+		return nil, name, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+
+	data, name, err := readData(name, file)
+	if err != nil {
+		return nil, name, fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, name, nil
+}
+
+// formatParser returns the parser of FileRegistry for a format
+func formatParser(format string) (FileParser, error) {
+	parser, ok := FileRegistry[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file format: %s", format)
+	}
+	return parser, nil
+}
+
+// FileStreamer decodes the elements of r one at a time.
+// For each element, next is called with a function decoding it into the given pointer,
+// the streamer stops reading as soon as next returns false.
+type FileStreamer func(r io.Reader, elem reflect.Type, next func(decode func(any) error) bool) error
+
+// Formats of FileRegistry without a streamer are fully parsed before being iterated
+var StreamRegistry = map[string]FileStreamer{
+	"json":   streamJSON,
+	"yaml":   streamYAML,
+	"xml":    streamXML,
+	"jsonl":  jsonLinesFormat.stream,
+	"ndjson": jsonLinesFormat.stream,
+}
+
+// StreamFile deserializes the elements of a file one at a time, see LoadFile.
+// Reading stops as soon as `limit` elements were yielded (use 0 for no limit), the loop is broken or ctx is done.
+// The sequence ends after the first error.
+func StreamFile[S any](ctx context.Context, filePath string, limit int, validation bool) iter.Seq2[S, error] {
+	return func(yield func(S, error) bool) {
+		var zero S
+		file, err := os.Open(filePath)
+		if err != nil {
+			yield(zero, fmt.Errorf("failed to read file: %w", err))
+			return
+		}
+		defer file.Close()
+
+		decompressed, name, err := decompress(filePath, file)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		reader := bufio.NewReaderSize(decompressed, sniffSize)
+		head, _ := reader.Peek(sniffSize)
+		detection, err := DetectFormat(name, head)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		ext := detection.Format
+		streamer, ok := StreamRegistry[ext]
+		if !ok {
+			parser, err := formatParser(ext)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			streamer = parsedStreamer(parser)
+		}
+
+		var validate *validator.Validate
+		if validation {
+			validate = validator.New()
+		}
+
+		count := 0
+		stopped := false
+		err = streamer(reader, reflect.TypeFor[S](), func(decode func(any) error) bool {
+			if err := ctx.Err(); err != nil {
+				stopped = true
+				yield(zero, err)
+				return false
+			}
+
+			var elem S
+			if err := decode(&elem); err != nil {
+				stopped = true
+				yield(zero, fmt.Errorf("failed to parse %s element %d: %w", ext, count, err))
+				return false
+			}
+			if validate != nil {
+				if err := validate.Struct(elem); err != nil {
+					stopped = true
+					yield(zero, fmt.Errorf("validation failed: %w", elementLoadErrors(count, elem, ext, err)))
+					return false
+				}
+			}
+
+			count++
+			if !yield(elem, nil) || (limit > 0 && count >= limit) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if err != nil && !stopped {
+			yield(zero, fmt.Errorf("failed to parse %s: %w", ext, err))
+		}
+	}
+}
+
+// parsedStreamer iterates the elements of a format without streaming support, once fully parsed
+func parsedStreamer(parser FileParser) FileStreamer {
+	return func(r io.Reader, elem reflect.Type, next func(decode func(any) error) bool) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		items := reflect.New(reflect.SliceOf(elem))
+		if err := parser(data, items.Interface()); err != nil {
+			single := reflect.New(elem)
+			if err2 := parser(data, single.Interface()); err2 != nil {
+				return err
+			}
+			items.Elem().Set(reflect.Append(items.Elem(), single.Elem()))
+		}
+
+		for i := 0; i < items.Elem().Len(); i++ {
+			item := items.Elem().Index(i)
+			decode := func(v any) error {
+				reflect.ValueOf(v).Elem().Set(item)
+				return nil
+			}
+			if !next(decode) {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+// streamJSON decodes the elements of a top-level array one at a time, or a single object
+func streamJSON(r io.Reader, elem reflect.Type, next func(decode func(any) error) bool) error {
+	reader := bufio.NewReader(r)
+	first, err := peekNonSpace(reader)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(reader)
+	if first != '[' {
+		next(decoder.Decode)
+		return nil
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		if !next(decoder.Decode) {
+			return nil
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+// streamYAML decodes documents one at a time, a document holding a sequence gives one element per item
+func streamYAML(r io.Reader, elem reflect.Type, next func(decode func(any) error) bool) error {
+	decoder := yaml.NewDecoder(r)
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(document.Content) == 0 {
+			continue
+		}
+
+		content := document.Content[0]
+		if content.Kind != yaml.SequenceNode {
+			if !next(content.Decode) {
+				return nil
+			}
+			continue
+		}
+		for _, item := range content.Content {
+			if !next(item.Decode) {
+				return nil
+			}
+		}
+	}
+}
+
+// streamXML decodes the children of the root element named after the element type, one at a time.
+// The root element itself is decoded when it has this name.
+// Without an XMLName field, the type name matches the root ignoring case and every child is decoded.
+func streamXML(r io.Reader, elem reflect.Type, next func(decode func(any) error) bool) error {
+	name, explicit := xmlElementName(elem)
+	decoder := xml.NewDecoder(r)
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch start := token.(type) {
+		case xml.StartElement:
+			if depth == 0 && (start.Name.Local == name || (!explicit && strings.EqualFold(start.Name.Local, name))) {
+				next(func(v any) error { return decoder.DecodeElement(v, &start) })
+				return nil
+			}
+			if depth == 1 && (!explicit || start.Name.Local == name) {
+				if !next(func(v any) error { return decoder.DecodeElement(v, &start) }) {
+					return nil
+				}
+				continue
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// xmlCollection returns the elements of a root holding several elements named after elem, or of the <items> root
+// written by SaveFile. Other documents, such as a single struct whose children are its fields, aren't collections.
+func xmlCollection(data []byte, elem reflect.Type) ([]dataSpan, bool) {
+	name, explicit := xmlElementName(elem)
+	named := func(local string) bool {
+		return name != "" && (local == name || (!explicit && strings.EqualFold(local, name)))
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root := ""
+	var spans []dataSpan
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, false
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			if root == "" {
+				root = element.Name.Local
+				if named(root) {
+					return nil, false
+				}
+				continue
+			}
+			if !named(element.Name.Local) {
+				return nil, false
+			}
+			if err := decoder.Skip(); err != nil {
+				return nil, false
+			}
+			spans = append(spans, dataSpan{start: offset, end: decoder.InputOffset()})
+		case xml.EndElement:
+			// children are skipped, this is the end of the root
+			return spans, root == "items" || len(spans) > 1
+		}
+	}
+}
+
+// xmlElementName returns the element name of a type, from its XMLName field or its type name.
+// explicit is false when the name doesn't come from XMLName.
+func xmlElementName(typ reflect.Type) (name string, explicit bool) {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return "", false
+	}
+	if field, ok := typ.FieldByName("XMLName"); ok {
+		if name, _, _ := strings.Cut(field.Tag.Get("xml"), ","); name != "" {
+			if idx := strings.LastIndex(name, " "); idx >= 0 {
+				name = name[idx+1:]
+			}
+			return name, true
+		}
+	}
+	return typ.Name(), false
+}
+
+// peekNonSpace returns the first byte of reader that is neither a space nor a BOM, without consuming it
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.ReadByte()
+		case 0xEF:
+			if bom, err := reader.Peek(3); err == nil && string(bom) == "\xEF\xBB\xBF" {
+				reader.Discard(3)
+				continue
+			}
+			return b[0], nil
+		default:
+			return b[0], nil
+		}
+	}
+}
+
 // Adapted from Gosamples website
 func Unzip(ctx context.Context, source, destination string) error {
     reader, err := zip.OpenReader(source)
@@ -126,3 +554,32 @@ func unzipFile(f *zip.File, destination string) error {
     }
     return nil
 }
+
+// writeFileAtomic writes data to a temporary file of the same directory and renames it over filePath,
+// so readers never see a partially written file
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+    temp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp*")
+    if err != nil {
+        return fmt.Errorf("failed to create temporary file: %w", err)
+    }
+    defer os.Remove(temp.Name())
+
+    if _, err := temp.Write(data); err != nil {
+        temp.Close()
+        return fmt.Errorf("failed to write file: %w", err)
+    }
+    if err := temp.Sync(); err != nil {
+        temp.Close()
+        return fmt.Errorf("failed to sync file: %w", err)
+    }
+    if err := temp.Close(); err != nil {
+        return fmt.Errorf("failed to close file: %w", err)
+    }
+    if err := os.Chmod(temp.Name(), perm); err != nil {
+        return fmt.Errorf("failed to set permissions: %w", err)
+    }
+    if err := os.Rename(temp.Name(), filePath); err != nil {
+        return fmt.Errorf("failed to rename file: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,79 @@
+package simple
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Decompressor wraps a compressed reader
+type Decompressor func(io.Reader) (io.Reader, error)
+
+// DecompressorRegistry maps the last extension of a compound one, such as gz in records.json.gz, to its decompressor
+var DecompressorRegistry = map[string]Decompressor{
+	"gz":   func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	"bz2":  func(r io.Reader) (io.Reader, error) { return bzip2.NewReader(r), nil },
+	"zz":   func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+	"zlib": func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+}
+
+// MaxDecompressedSize is the maximum amount of bytes read from a compressed file, to protect against
+// decompression bombs. Use 0 for no limit.
+var MaxDecompressedSize int64 = 1 << 30
+
+var ErrDecompressedTooLarge = errors.New("decompressed data exceeds MaxDecompressedSize")
+
+// decompressedReader fails once more than remaining bytes were read
+type decompressedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (d *decompressedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > d.remaining+1 {
+		p = p[:d.remaining+1]
+	}
+	n, err := d.r.Read(p)
+	if int64(n) > d.remaining {
+		n = int(d.remaining)
+		d.remaining = 0
+		return n, fmt.Errorf("%w (%d bytes)", ErrDecompressedTooLarge, MaxDecompressedSize)
+	}
+	d.remaining -= int64(n)
+	return n, err
+}
+
+// decompress wraps r with the decompressor matching the extension of name.
+// It returns the name without this extension, so records.json.gz gives records.json
+func decompress(name string, r io.Reader) (io.Reader, string, error) {
+	ext := path.Ext(name)
+	decompressor, ok := DecompressorRegistry[strings.TrimPrefix(strings.ToLower(ext), ".")]
+	if !ok {
+		return r, name, nil
+	}
+
+	decompressed, err := decompressor(r)
+	if err != nil {
+		return nil, name, fmt.Errorf("failed to decompress %s: %w", ext, err)
+	}
+	if MaxDecompressedSize > 0 {
+		decompressed = &decompressedReader{r: decompressed, remaining: MaxDecompressedSize}
+	}
+	return decompressed, strings.TrimSuffix(name, ext), nil
+}
+
+// readData reads r, decompressed according to the extension of name, and returns the name of the decompressed data
+func readData(name string, r io.Reader) ([]byte, string, error) {
+	r, name, err := decompress(name, r)
+	if err != nil {
+		return nil, name, err
+	}
+	data, err := io.ReadAll(r)
+	return data, name, err
+}
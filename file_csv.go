@@ -0,0 +1,321 @@
+package simple
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CSVFormat parses delimited files into a slice of structs, the first record being the header.
+// Columns are mapped to fields with the `csv:"name"` tag, or the field name ignoring case. Use `csv:"-"` to skip a field.
+// Empty cells keep the zero value, so pointer fields stay nil.
+type CSVFormat struct {
+	Comma            rune   // Field delimiter, ',' when zero
+	Comment          rune   // Lines starting with Comment are ignored, 0 to disable
+	LazyQuotes       bool   // Allow quotes in unquoted fields and non-doubled quotes in quoted fields
+	TrimLeadingSpace bool   // Ignore the leading white space of a field
+	TimeLayout       string // Layout of time.Time fields, time.RFC3339 when empty
+	Strict           bool   // Reject the columns without a field and the duplicate columns
+}
+
+var (
+	csvFormat = CSVFormat{Comma: ','}
+	tsvFormat = CSVFormat{Comma: '\t'}
+)
+
+// CSVError is a conversion or validation error located in a CSV file, Line and Column start at 1
+type CSVError struct {
+	Line   int
+	Column int
+	Header string
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("line %d, column %d (%s): %v", e.Line, e.Column, e.Header, e.Err)
+}
+
+func (e *CSVError) Unwrap() error { return e.Err }
+
+// Unmarshal decodes data into v, a pointer to a slice of structs. It can be registered in FileRegistry.
+func (f CSVFormat) Unmarshal(data []byte, v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("csv: expected a pointer to a slice, got %T", v)
+	}
+	slice := target.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: expected a slice of structs, got %s", slice.Type())
+	}
+
+	reader := f.reader(data)
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("csv: failed to read header: %w", err)
+	}
+	header = append([]string(nil), header...)
+	columns := csvColumns(structType, header)
+	if f.Strict {
+		if err := strictCSVHeader(reader, header, columns); err != nil {
+			return err
+		}
+	}
+
+	items := reflect.MakeSlice(slice.Type(), 0, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		elem := reflect.New(structType).Elem()
+		for col, field := range columns {
+			if field == nil || col >= len(record) || record[col] == "" {
+				continue
+			}
+			if err := f.setField(elem.FieldByIndex(field.Index), record[col]); err != nil {
+				if isSecretField(*field) {
+					err = fmt.Errorf("invalid %s value", field.Type)
+				}
+				line, _ := reader.FieldPos(col)
+				return &CSVError{Line: line, Column: col + 1, Header: header[col], Err: err}
+			}
+		}
+
+		if elemType.Kind() == reflect.Pointer {
+			elem = elem.Addr()
+		}
+		items = reflect.Append(items, elem)
+	}
+	slice.Set(items)
+	return nil
+}
+
+// Marshal encodes v, a slice of structs, with a header row. It can be registered in EncoderRegistry.
+func (f CSVFormat) Marshal(v any) ([]byte, error) {
+	items := reflect.ValueOf(v)
+	if items.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("csv: expected a slice, got %T", v)
+	}
+	structType := items.Type().Elem()
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("csv: expected a slice of structs, got %s", items.Type())
+	}
+
+	var fields []reflect.StructField
+	var header []string
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("csv"), ",")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		fields = append(fields, field)
+		header = append(header, tag)
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if f.Comma != 0 {
+		writer.Comma = f.Comma
+	}
+	writer.Write(header)
+	record := make([]string, len(fields))
+	for i := 0; i < items.Len(); i++ {
+		elem := reflect.Indirect(items.Index(i))
+		for col, field := range fields {
+			if !elem.IsValid() {
+				record[col] = ""
+				continue
+			}
+			cell, err := f.formatField(elem.FieldByIndex(field.Index))
+			if err != nil {
+				return nil, fmt.Errorf("element %d, column %s: %w", i, header[col], err)
+			}
+			record[col] = cell
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+// locate adds the line and column of the cell that failed the validation of element index
+func (f CSVFormat) locate(data []byte, index int, elem any, err error) error {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) || len(fieldErrors) == 0 {
+		return err
+	}
+
+	reader := f.reader(data)
+	header, readErr := reader.Read()
+	if readErr != nil {
+		return err
+	}
+	header = append([]string(nil), header...)
+	typ := reflect.TypeOf(elem)
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	columns := csvColumns(typ, header)
+	for i := 0; i <= index; i++ {
+		if _, readErr := reader.Read(); readErr != nil {
+			return err
+		}
+	}
+
+	for col, field := range columns {
+		if field != nil && field.Name == fieldErrors[0].StructField() {
+			line, _ := reader.FieldPos(col)
+			return &CSVError{Line: line, Column: col + 1, Header: header[col], Err: err}
+		}
+	}
+	return err
+}
+
+// reader returns a csv.Reader over data configured by f, without the UTF-8 BOM of data
+func (f CSVFormat) reader(data []byte) *csv.Reader {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))))
+	if f.Comma != 0 {
+		reader.Comma = f.Comma
+	}
+	reader.Comment = f.Comment
+	reader.LazyQuotes = f.LazyQuotes
+	reader.TrimLeadingSpace = f.TrimLeadingSpace
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	return reader
+}
+
+func (f CSVFormat) setField(value reflect.Value, raw string) error {
+	typ := value.Type()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeOf(time.Time{}) {
+		layout := f.TimeLayout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, raw)
+		if err != nil {
+			return err
+		}
+		if value.Kind() == reflect.Pointer {
+			value.Set(reflect.ValueOf(&t))
+		} else {
+			value.Set(reflect.ValueOf(t))
+		}
+		return nil
+	}
+	return setEnvValue(value, raw)
+}
+
+func (f CSVFormat) formatField(value reflect.Value) (string, error) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return "", nil
+		}
+		value = value.Elem()
+	}
+	if t, ok := value.Interface().(time.Time); ok {
+		layout := f.TimeLayout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return t.Format(layout), nil
+	}
+	if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, value.Len())
+		for i := range parts {
+			part, err := f.formatField(value.Index(i))
+			if err != nil {
+				return "", err
+			}
+			parts[i] = part
+		}
+		return strings.Join(parts, ","), nil
+	case reflect.Map, reflect.Struct, reflect.Interface, reflect.Func, reflect.Chan:
+		return "", fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return fmt.Sprint(value.Interface()), nil
+}
+
+// strictCSVHeader rejects the unknown and duplicate columns of the header, the last record read by reader
+func strictCSVHeader(reader *csv.Reader, header []string, columns []*reflect.StructField) error {
+	var errs LoadErrors
+	seen := make(map[string]bool)
+	for col, name := range header {
+		var err error
+		switch {
+		case seen[name]:
+			err = ErrDuplicateKey
+		case columns[col] == nil:
+			err = ErrUnknownField
+		default:
+			seen[name] = true
+			continue
+		}
+		line, _ := reader.FieldPos(col)
+		errs = append(errs, &LoadError{Element: -1, Path: "/" + escapePointer(name), Line: line, Column: col + 1, Err: fmt.Errorf("%w %q", err, name)})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// csvColumns returns the field of every header column, nil for the columns without a field
+func csvColumns(typ reflect.Type, header []string) []*reflect.StructField {
+	columns := make([]*reflect.StructField, len(header))
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() || field.Tag.Get("csv") == "-" {
+			continue
+		}
+		for col, name := range header {
+			if columns[col] == nil && csvHeaderMatches(field.Tag.Get("csv"), field.Name, name) {
+				columns[col] = &field
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// csvHeaderMatches reports whether a header column names the field with the given tag and name
+func csvHeaderMatches(tag, name, header string) bool {
+	header = strings.TrimSpace(header)
+	if tag, _, _ = strings.Cut(tag, ","); tag != "" {
+		return tag == header
+	}
+	return strings.EqualFold(name, header)
+}
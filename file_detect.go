@@ -0,0 +1,182 @@
+package simple
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// sniffSize is the amount of bytes inspected by the content detectors
+const sniffSize = 4096
+
+// FormatAliases maps file extensions to a key of FileRegistry
+var FormatAliases = map[string]string{
+	"yml":       "yaml",
+	"jsonlines": "jsonl",
+	"tml":       "toml",
+}
+
+// FormatDetector guesses a format from the first bytes of a file, stripped from their BOM and leading spaces
+type FormatDetector struct {
+	Name   string
+	Detect func(data []byte) (string, bool)
+}
+
+// FormatDetectors are tried in order when the extension of a file isn't in FileRegistry nor FormatAliases
+var FormatDetectors = []FormatDetector{
+	{Name: "xml-declaration", Detect: detectXML},
+	{Name: "json", Detect: detectJSON},
+	{Name: "json-lines", Detect: detectJSONLines},
+	{Name: "yaml-marker", Detect: detectYAMLMarker},
+	{Name: "toml-table", Detect: detectTOMLTable},
+	{Name: "toml-key", Detect: detectTOMLKey},
+	{Name: "yaml-key", Detect: detectYAMLKey},
+	{Name: "delimited", Detect: detectDelimited},
+}
+
+// FormatDetection is the format of a file and what decided it : "forced", "extension", "alias"
+// or the name of a FormatDetector
+type FormatDetection struct {
+	Format   string
+	Detector string
+}
+
+func (d FormatDetection) String() string {
+	return fmt.Sprintf("%s (detected by %s)", d.Format, d.Detector)
+}
+
+// DetectFormat returns the format of a file from its name, then from its content
+func DetectFormat(name string, data []byte) (FormatDetection, error) {
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(name)), ".")
+	if _, ok := FileRegistry[ext]; ok {
+		return FormatDetection{Format: ext, Detector: "extension"}, nil
+	}
+	if alias, ok := FormatAliases[ext]; ok {
+		return FormatDetection{Format: alias, Detector: "alias"}, nil
+	}
+
+	if len(data) > sniffSize {
+		data = data[:sniffSize]
+	}
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF")), " \t\r\n")
+	for _, detector := range FormatDetectors {
+		if format, ok := detector.Detect(data); ok {
+			return FormatDetection{Format: format, Detector: detector.Name}, nil
+		}
+	}
+	return FormatDetection{}, fmt.Errorf("unsupported file format: %s", ext)
+}
+
+// forcedFormat returns the detection of a format given by the caller, such as json, .json or yml
+func forcedFormat(format string) FormatDetection {
+	format = strings.TrimPrefix(strings.ToLower(format), ".")
+	if alias, ok := FormatAliases[format]; ok {
+		format = alias
+	}
+	return FormatDetection{Format: format, Detector: "forced"}
+}
+
+var (
+	tomlTablePattern = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_."-]+\s*\]\]?\s*(#.*)?$`)
+	tomlKeyPattern   = regexp.MustCompile(`^[A-Za-z0-9_."-]+\s*=\s*\S`)
+	yamlKeyPattern   = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#:][^:#]*):(\s|$)`)
+)
+
+func detectXML(data []byte) (string, bool) {
+	return "xml", bytes.HasPrefix(data, []byte("<?xml")) || (bytes.HasPrefix(data, []byte("<")) && !bytes.HasPrefix(data, []byte("<<")))
+}
+
+func detectJSON(data []byte) (string, bool) {
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return "", false
+	}
+	if json.Valid(data) {
+		return "json", true
+	}
+	// several values are json lines, otherwise data may be truncated so only its first tokens are checked
+	if json.Valid(firstLine(data)) {
+		return "", false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for i := 0; i < 4; i++ {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", false
+		}
+	}
+	return "json", true
+}
+
+func detectJSONLines(data []byte) (string, bool) {
+	lines := nonEmptyLines(data)
+	if len(lines) < 2 || lines[0][0] != '{' {
+		return "", false
+	}
+	// the last line may be truncated
+	for _, line := range lines[:len(lines)-1] {
+		if !json.Valid(line) {
+			return "", false
+		}
+	}
+	return "jsonl", true
+}
+
+func detectYAMLMarker(data []byte) (string, bool) {
+	return "yaml", bytes.HasPrefix(data, []byte("---")) || bytes.HasPrefix(data, []byte("%YAML"))
+}
+
+func detectTOMLTable(data []byte) (string, bool) {
+	return "toml", tomlTablePattern.Match(firstLine(data))
+}
+
+func detectTOMLKey(data []byte) (string, bool) {
+	return "toml", tomlKeyPattern.Match(firstLine(data))
+}
+
+func detectYAMLKey(data []byte) (string, bool) {
+	line := firstLine(data)
+	return "yaml", yamlKeyPattern.Match(line) || bytes.HasPrefix(line, []byte("- "))
+}
+
+// detectDelimited recognizes csv and tsv files, whose first lines have the same amount of delimiters
+func detectDelimited(data []byte) (string, bool) {
+	lines := nonEmptyLines(data)
+	if len(lines) < 2 {
+		return "", false
+	}
+	for _, candidate := range []struct {
+		format    string
+		delimiter []byte
+	}{{"tsv", []byte("\t")}, {"csv", []byte(",")}} {
+		count := bytes.Count(lines[0], candidate.delimiter)
+		if count > 0 && bytes.Count(lines[1], candidate.delimiter) == count {
+			return candidate.format, true
+		}
+	}
+	return "", false
+}
+
+// firstLine returns the first line of data which isn't empty nor a comment
+func firstLine(data []byte) []byte {
+	for _, line := range nonEmptyLines(data) {
+		if line[0] != '#' {
+			return line
+		}
+	}
+	return nil
+}
+
+func nonEmptyLines(data []byte) [][]byte {
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,477 @@
+package simple
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadError is a parse or validation error located in the loaded data
+type LoadError struct {
+	Element int    // Index of the element, -1 when unknown
+	Path    string // JSON pointer of the field in the element, such as /db/port
+	Tag     string // Validator tag that failed, such as required
+	Line    int    // Line in the source starting at 1, 0 when unknown
+	Column  int    // Column in the source starting at 1, 0 when unknown
+	Err     error
+}
+
+func (e *LoadError) Error() string {
+	var location []string
+	if e.Element >= 0 {
+		location = append(location, fmt.Sprintf("element %d", e.Element))
+	}
+	if e.Path != "" {
+		location = append(location, e.Path)
+	}
+	if e.Line > 0 {
+		location = append(location, fmt.Sprintf("line %d, column %d", e.Line, e.Column))
+	}
+
+	// located errors already gave their location to e
+	message := fmt.Sprint(e.Err)
+	switch err := e.Err.(type) {
+	case *CSVError:
+		message = fmt.Sprint(err.Err)
+	case *JSONLineError:
+		message = fmt.Sprint(err.Err)
+	}
+	if e.Tag != "" {
+		message = fmt.Sprintf("failed on the '%s' tag", e.Tag)
+	}
+	if len(location) == 0 {
+		return message
+	}
+	return strings.Join(location, ", ") + ": " + message
+}
+
+func (e *LoadError) Unwrap() error { return e.Err }
+
+// LoadErrors are all the errors found while loading data
+type LoadErrors []*LoadError
+
+func (e LoadErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e LoadErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Report returns one line per error, such as `element 3, /db/port, line 12, column 5: failed on the 'gt' tag`
+func (e LoadErrors) Report() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d error(s) while loading:\n", len(e))
+	for _, err := range e {
+		builder.WriteString("  - " + err.Error() + "\n")
+	}
+	return builder.String()
+}
+
+type sourcePosition struct {
+	Line   int
+	Column int
+}
+
+// sourcePositions index the JSON pointers of a document with their position
+var sourcePositions = map[string]func(data []byte) map[string]sourcePosition{
+	"json": jsonPositions,
+	"yaml": yamlPositions,
+	"toml": tomlPositions,
+	"xml":  xmlPositions,
+}
+
+// fieldTags are the struct tags naming the fields of a format
+var fieldTags = map[string]string{
+	"json":   "json",
+	"jsonl":  "json",
+	"ndjson": "json",
+	"yaml":   "yaml",
+	"toml":   "toml",
+	"xml":    "xml",
+	"csv":    "csv",
+	"tsv":    "csv",
+}
+
+var (
+	lineColumnPattern = regexp.MustCompile(`^\((\d+), (\d+)\)`)
+	linePattern       = regexp.MustCompile(`line (\d+)`)
+)
+
+// parseLoadError locates a parser error in data
+func parseLoadError(data []byte, err error) *LoadError {
+	loadErr := &LoadError{Element: -1, Err: err}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var xmlErr *xml.SyntaxError
+	var csvErr *CSVError
+	var csvParseErr *csv.ParseError
+	var lineErr *JSONLineError
+	switch {
+	// located errors first, the errors they wrap have positions relative to a line
+	case errors.As(err, &csvErr):
+		loadErr.Path = "/" + escapePointer(csvErr.Header)
+		loadErr.Line, loadErr.Column, loadErr.Err = csvErr.Line, csvErr.Column, csvErr
+	case errors.As(err, &lineErr):
+		loadErr.Line, loadErr.Err = lineErr.Line, lineErr
+	case errors.As(err, &syntaxErr):
+		// the offset is after the invalid byte
+		loadErr.Line, loadErr.Column = offsetPosition(data, max(syntaxErr.Offset-1, 0))
+	case errors.As(err, &typeErr):
+		loadErr.Line, loadErr.Column = offsetPosition(data, typeErr.Offset)
+		if typeErr.Field != "" {
+			loadErr.Path = "/" + strings.ReplaceAll(typeErr.Field, ".", "/")
+		}
+	case errors.As(err, &xmlErr):
+		loadErr.Line = xmlErr.Line
+	case errors.As(err, &csvParseErr):
+		loadErr.Line, loadErr.Column = csvParseErr.Line, csvParseErr.Column
+	default:
+		// go-toml reports (line, column) and yaml reports line N in their messages
+		if match := lineColumnPattern.FindStringSubmatch(err.Error()); match != nil {
+			loadErr.Line, _ = strconv.Atoi(match[1])
+			loadErr.Column, _ = strconv.Atoi(match[2])
+		} else if match := linePattern.FindStringSubmatch(err.Error()); match != nil {
+			loadErr.Line, _ = strconv.Atoi(match[1])
+		}
+	}
+	return loadErr
+}
+
+// spanLoadErrors locates the parse error of element index, parsed alone from span of data
+func spanLoadErrors(data []byte, span dataSpan, index int, err error) LoadErrors {
+	var errs LoadErrors
+	if !errors.As(err, &errs) {
+		errs = LoadErrors{parseLoadError(data[span.start:span.end], err)}
+	}
+	line, column := offsetPosition(data, span.start)
+	for _, loadErr := range errs {
+		loadErr.Element = index
+		if loadErr.Line == 1 && loadErr.Column > 0 {
+			loadErr.Column += column - 1
+		}
+		if loadErr.Line > 0 {
+			loadErr.Line += line - 1
+		}
+	}
+	return errs
+}
+
+// validationLoadErrors validates every element and returns all the failing fields
+func validationLoadErrors[S any](data []byte, format string, items []S, single bool) LoadErrors {
+	var errs LoadErrors
+	var positions map[string]sourcePosition
+	validate := validator.New()
+
+	for i, elem := range items {
+		err := validate.Struct(elem)
+		if err == nil {
+			continue
+		}
+		elemErrs := elementLoadErrors(i, elem, format, err)
+		if positions == nil {
+			positions = make(map[string]sourcePosition)
+			if index, ok := sourcePositions[format]; ok {
+				positions = index(data)
+			}
+		}
+
+		for _, loadErr := range elemErrs {
+			fieldErr, ok := loadErr.Err.(validator.FieldError)
+			if !ok {
+				errs = append(errs, loadErr)
+				continue
+			}
+
+			if locate, ok := fileLocators[format]; ok {
+				located := locate(data, i, elem, validator.ValidationErrors{fieldErr})
+				var csvErr *CSVError
+				var lineErr *JSONLineError
+				if errors.As(located, &csvErr) {
+					loadErr.Line, loadErr.Column = csvErr.Line, csvErr.Column
+				} else if errors.As(located, &lineErr) {
+					loadErr.Line = lineErr.Line
+				}
+				loadErr.Err = located
+			} else if position, ok := lookupPosition(positions, i, loadErr.Path, single); ok {
+				loadErr.Line, loadErr.Column = position.Line, position.Column
+			}
+			errs = append(errs, loadErr)
+		}
+	}
+	return errs
+}
+
+// elementLoadErrors converts the validation error of element index into one LoadError per failing field, without position
+func elementLoadErrors(index int, elem any, format string, err error) LoadErrors {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return LoadErrors{{Element: index, Err: err}}
+	}
+
+	errs := make(LoadErrors, len(fieldErrors))
+	for i, fieldErr := range fieldErrors {
+		errs[i] = &LoadError{
+			Element: index,
+			Path:    fieldPointer(reflect.TypeOf(elem), fieldErr.StructNamespace(), fieldTags[format]),
+			Tag:     fieldErr.Tag(),
+			Err:     fieldErr,
+		}
+	}
+	return errs
+}
+
+// lookupPosition returns the position of the field, or of its closest parent present in the source
+func lookupPosition(positions map[string]sourcePosition, index int, pointer string, single bool) (sourcePosition, bool) {
+	for {
+		if !single {
+			if position, ok := positions["/"+strconv.Itoa(index)+pointer]; ok {
+				return position, true
+			}
+		}
+		if position, ok := positions[pointer]; ok && (pointer != "" || single) {
+			return position, true
+		}
+		if pointer == "" {
+			return sourcePosition{}, false
+		}
+		pointer = pointer[:strings.LastIndex(pointer, "/")]
+	}
+}
+
+// fieldPointer converts a validator namespace such as Record.DB.Hosts[1] into a JSON pointer such as /db/hosts/1,
+// named after the tag of the format
+func fieldPointer(typ reflect.Type, namespace, tag string) string {
+	segments := strings.Split(namespace, ".")[1:]
+	var pointer strings.Builder
+	for _, segment := range segments {
+		name, indexes, _ := strings.Cut(segment, "[")
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			break
+		}
+		field, ok := typ.FieldByName(name)
+		if !ok {
+			break
+		}
+		pointer.WriteString("/" + fieldName(field, tag))
+		typ = field.Type
+
+		for indexes != "" {
+			index, rest, _ := strings.Cut(indexes, "]")
+			pointer.WriteString("/" + escapePointer(index))
+			indexes = strings.TrimPrefix(rest, "[")
+			for typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+			}
+			if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+				typ = typ.Elem()
+			}
+		}
+	}
+	return pointer.String()
+}
+
+// fieldName returns the name of a field in a format, from its tag or the default of the decoder
+func fieldName(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if tag == "xml" {
+		if idx := strings.LastIndexAny(name, " >"); idx >= 0 {
+			name = name[idx+1:]
+		}
+	}
+	if name == "" || name == "-" {
+		name = field.Name
+		if tag == "yaml" {
+			name = strings.ToLower(name)
+		}
+	}
+	return name
+}
+
+// offsetPosition converts a byte offset into a line and a column
+func offsetPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	return line, int(offset) - bytes.LastIndexByte(before, '\n')
+}
+
+func jsonPositions(data []byte) map[string]sourcePosition {
+	offsets := make(map[string]int64)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var walk func(pointer string) error
+	walk = func(pointer string) error {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := token.(json.Delim)
+		if !ok {
+			return nil
+		}
+		for i := 0; decoder.More(); i++ {
+			start := skipJSONSeparators(data, decoder.InputOffset())
+			child := pointer + "/" + strconv.Itoa(i)
+			if delim == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				child = pointer + "/" + escapePointer(fmt.Sprint(key))
+			}
+			offsets[child] = start
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	}
+
+	offsets[""] = skipJSONSeparators(data, 0)
+	walk("")
+	positions := make(map[string]sourcePosition, len(offsets))
+	for pointer, offset := range offsets {
+		line, column := offsetPosition(data, offset)
+		positions[pointer] = sourcePosition{Line: line, Column: column}
+	}
+	return positions
+}
+
+// skipJSONSeparators returns the offset of the next token
+func skipJSONSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,:\xEF\xBB\xBF", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func yamlPositions(data []byte) map[string]sourcePosition {
+	positions := make(map[string]sourcePosition)
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil || len(document.Content) == 0 {
+		return positions
+	}
+
+	var walk func(node *yaml.Node, pointer string)
+	walk = func(node *yaml.Node, pointer string) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i]
+				child := pointer + "/" + escapePointer(key.Value)
+				positions[child] = sourcePosition{Line: key.Line, Column: key.Column}
+				walk(node.Content[i+1], child)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				child := pointer + "/" + strconv.Itoa(i)
+				positions[child] = sourcePosition{Line: item.Line, Column: item.Column}
+				walk(item, child)
+			}
+		}
+	}
+	root := document.Content[0]
+	positions[""] = sourcePosition{Line: root.Line, Column: root.Column}
+	walk(root, "")
+	return positions
+}
+
+func tomlPositions(data []byte) map[string]sourcePosition {
+	positions := make(map[string]sourcePosition)
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return positions
+	}
+
+	var walk func(tree *toml.Tree, pointer string)
+	walk = func(tree *toml.Tree, pointer string) {
+		for _, key := range tree.Keys() {
+			child := pointer + "/" + escapePointer(key)
+			position := tree.GetPositionPath([]string{key})
+			positions[child] = sourcePosition{Line: position.Line, Column: position.Col}
+			switch value := tree.GetPath([]string{key}).(type) {
+			case *toml.Tree:
+				walk(value, child)
+			case []*toml.Tree:
+				for i, item := range value {
+					itemPointer := child + "/" + strconv.Itoa(i)
+					positions[itemPointer] = sourcePosition{Line: item.Position().Line, Column: item.Position().Col}
+					walk(item, itemPointer)
+				}
+			}
+		}
+	}
+	positions[""] = sourcePosition{Line: 1, Column: 1}
+	walk(tree, "")
+	return positions
+}
+
+// xmlPositions index the elements and attributes under the root element, repeated elements are numbered
+func xmlPositions(data []byte) map[string]sourcePosition {
+	positions := make(map[string]sourcePosition)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	type level struct {
+		pointer string
+		counts  map[string]int
+	}
+	var stack []level
+
+	for {
+		line, column := decoder.InputPos()
+		token, err := decoder.Token()
+		if err != nil {
+			return positions
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			pointer := ""
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				name := escapePointer(element.Name.Local)
+				pointer = parent.pointer + "/" + name
+				if _, ok := positions[pointer]; !ok {
+					positions[pointer] = sourcePosition{Line: line, Column: column}
+				}
+				pointer += "/" + strconv.Itoa(parent.counts[name])
+				parent.counts[name]++
+			}
+			positions[pointer] = sourcePosition{Line: line, Column: column}
+			for _, attr := range element.Attr {
+				positions[pointer+"/"+escapePointer(attr.Name.Local)] = sourcePosition{Line: line, Column: column}
+			}
+			stack = append(stack, level{pointer: strings.TrimSuffix(pointer, "/0"), counts: make(map[string]int)})
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,146 @@
+package simple
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// JSONLinesFormat parses newline-delimited JSON (jsonl, ndjson), one value per line. Blank lines are ignored.
+type JSONLinesFormat struct {
+	// OnMalformed is called for every line that can't be decoded, the line is then skipped.
+	// Parsing stops at the first malformed line when nil.
+	OnMalformed func(err *JSONLineError)
+	// Strict rejects the unknown fields and the duplicate keys of the lines, as malformed lines
+	Strict bool
+}
+
+var jsonLinesFormat = JSONLinesFormat{}
+
+// JSONLineError is an error on a line of a JSON Lines file, Line starts at 1
+type JSONLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *JSONLineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *JSONLineError) Unwrap() error { return e.Err }
+
+// Unmarshal decodes data into v, a pointer to a slice. It can be registered in FileRegistry.
+func (f JSONLinesFormat) Unmarshal(data []byte, v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("jsonl: expected a pointer to a slice, got %T", v)
+	}
+	slice := target.Elem()
+
+	items := reflect.MakeSlice(slice.Type(), 0, 0)
+	err := f.lines(bytes.NewReader(data), slice.Type().Elem(), func(line int, value reflect.Value) bool {
+		items = reflect.Append(items, value)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	slice.Set(items)
+	return nil
+}
+
+// stream decodes the lines of r one at a time, it is the FileStreamer of the format
+func (f JSONLinesFormat) stream(r io.Reader, elem reflect.Type, next func(decode func(any) error) bool) error {
+	return f.lines(r, elem, func(line int, value reflect.Value) bool {
+		return next(func(v any) error {
+			reflect.ValueOf(v).Elem().Set(value)
+			return nil
+		})
+	})
+}
+
+// locate adds the line of element index to its validation error
+func (f JSONLinesFormat) locate(data []byte, index int, elem any, err error) error {
+	located := err
+	count := 0
+	f.lines(bytes.NewReader(data), reflect.TypeOf(elem), func(line int, value reflect.Value) bool {
+		if count == index {
+			located = &JSONLineError{Line: line, Err: err}
+			return false
+		}
+		count++
+		return true
+	})
+	return located
+}
+
+// lines calls yield with every decoded line until it returns false
+func (f JSONLinesFormat) lines(r io.Reader, elem reflect.Type, yield func(line int, value reflect.Value) bool) error {
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		text, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if line == 1 {
+			text = bytes.TrimPrefix(text, []byte("\xEF\xBB\xBF"))
+		}
+		if text = bytes.TrimSpace(text); len(text) > 0 {
+			value := reflect.New(elem)
+			if decodeErr := f.decode(text, line, value); decodeErr != nil {
+				lineErr := &JSONLineError{Line: line, Err: decodeErr}
+				if f.OnMalformed == nil {
+					return lineErr
+				}
+				f.OnMalformed(lineErr)
+			} else if !yield(line, value.Elem()) {
+				return nil
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// decode unmarshals a line into value, strict errors are located in the file
+func (f JSONLinesFormat) decode(text []byte, line int, value reflect.Value) error {
+	if f.Strict {
+		if root, err := jsonKeyTree(text); err == nil {
+			if err := checkKeys(root, value.Type(), "json"); err != nil {
+				var errs LoadErrors
+				if !errors.As(err, &errs) {
+					return err
+				}
+				for _, loadErr := range errs {
+					loadErr.Element, loadErr.Line = -1, line
+				}
+				return errs
+			}
+		}
+	}
+	return json.Unmarshal(text, value.Interface())
+}
+
+// Marshal encodes v, a slice, with one value per line. It can be registered in EncoderRegistry.
+func (f JSONLinesFormat) Marshal(v any) ([]byte, error) {
+	items := reflect.ValueOf(v)
+	if items.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("jsonl: expected a slice, got %T", v)
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for i := 0; i < items.Len(); i++ {
+		if err := encoder.Encode(items.Index(i).Interface()); err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,115 @@
+package simple
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEncoder serializes a slice of items
+type FileEncoder func(any) ([]byte, error)
+
+// EncoderRegistry is the serialization counterpart of FileRegistry
+var EncoderRegistry = map[string]FileEncoder{
+	"json":   encodeJSON,
+	"yaml":   yaml.Marshal,
+	"toml":   encodeTOML,
+	"xml":    encodeXML,
+	"csv":    csvFormat.Marshal,
+	"tsv":    tsvFormat.Marshal,
+	"jsonl":  jsonLinesFormat.Marshal,
+	"ndjson": jsonLinesFormat.Marshal,
+}
+
+// SaveOptions configures SaveFile
+type SaveOptions struct {
+	Perm       os.FileMode // Permissions of the file, 0644 when zero
+	Validation bool        // Apply tag validation from validator package before writing
+}
+
+// Serialize items into the given data type (json, yaml, toml, xml, csv, tsv, jsonl or ndjson).
+// The file is written to a temporary file renamed over filePath, so readers never see a partial file.
+// Secret fields are written redacted in every format, their value is lost.
+func SaveFile[S any](filePath string, items []S, opts SaveOptions) error {
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(filePath)), ".")
+	if alias, ok := FormatAliases[ext]; ok {
+		ext = alias
+	}
+	encoder, ok := EncoderRegistry[ext]
+	if !ok {
+		return fmt.Errorf("unsupported file format: %s", ext)
+	}
+
+	if opts.Validation {
+		validate := validator.New()
+		for i, elem := range items {
+			if err := validate.Struct(elem); err != nil {
+				return fmt.Errorf("validation failed for element %d: %w", i, err)
+			}
+		}
+	}
+
+	data, err := encoder(items)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", ext, err)
+	}
+
+	perm := opts.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	return writeFileAtomic(filePath, data, perm)
+}
+
+func encodeJSON(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// encodeTOML encodes a single item, as a TOML document can't hold a list at its root
+func encodeTOML(v any) ([]byte, error) {
+	items := reflect.ValueOf(v)
+	if items.Kind() == reflect.Slice {
+		if items.Len() != 1 {
+			return nil, fmt.Errorf("a toml file holds a single element, got %d", items.Len())
+		}
+		v = items.Index(0).Interface()
+	}
+	return toml.Marshal(v)
+}
+
+// encodeXML encodes a single item as the root element, several items are wrapped in an <items> element
+func encodeXML(v any) ([]byte, error) {
+	items := reflect.ValueOf(v)
+	if items.Kind() == reflect.Slice && items.Len() == 1 {
+		v = items.Index(0).Interface()
+	} else if items.Kind() == reflect.Slice {
+		name, _ := xmlElementName(items.Type().Elem())
+		if name == "" {
+			name = "item"
+		}
+		wrapper := reflect.New(reflect.StructOf([]reflect.StructField{
+			{Name: "XMLName", Type: reflect.TypeOf(xml.Name{}), Tag: `xml:"items"`},
+			{Name: "Items", Type: items.Type(), Tag: reflect.StructTag(`xml:"` + name + `"`)},
+		})).Elem()
+		wrapper.Field(1).Set(items)
+		v = wrapper.Interface()
+	}
+
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
@@ -0,0 +1,492 @@
+package simple
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownField = errors.New("unknown field")
+	ErrDuplicateKey = errors.New("duplicate key")
+)
+
+// StrictRegistry pairs the formats of FileRegistry with parsers rejecting unknown fields and duplicate keys.
+// Errors are LoadErrors giving the path of every offending key.
+var StrictRegistry = map[string]FileParser{
+	"json":   strictParser(jsonKeyTree, "json", json.Unmarshal),
+	"yaml":   strictParser(yamlKeyTree, "yaml", yaml.Unmarshal),
+	"toml":   strictParser(tomlKeyTree, "toml", toml.Unmarshal),
+	"xml":    strictXML,
+	"csv":    CSVFormat{Comma: ',', Strict: true}.Unmarshal,
+	"tsv":    CSVFormat{Comma: '\t', Strict: true}.Unmarshal,
+	"jsonl":  JSONLinesFormat{Strict: true}.Unmarshal,
+	"ndjson": JSONLinesFormat{Strict: true}.Unmarshal,
+}
+
+// LoadFileStrict is LoadFile with the parsers of StrictRegistry, so typos in keys are errors instead of being ignored.
+// Formats missing from StrictRegistry, such as custom ones, are parsed by FileRegistry without these checks.
+func LoadFileStrict[S any](filePath string, limit int, validation bool) ([]S, error) {
+	return loadFileWith[S](strictParsers(), filePath, os.Open, limit, validation)
+}
+
+// LoadFSStrict is LoadFS with the parsers of StrictRegistry, see LoadFileStrict
+func LoadFSStrict[S any](fsys fs.FS, name string, limit int, validation bool) ([]S, error) {
+	return loadFileWith[S](strictParsers(), name, fsys.Open, limit, validation)
+}
+
+// LoadReaderStrict is LoadReader with the parsers of StrictRegistry, see LoadFileStrict
+func LoadReaderStrict[S any](r io.Reader, format string, limit int, validation bool) ([]S, error) {
+	return loadReaderWith[S](strictParsers(), r, format, limit, validation)
+}
+
+// strictParsers returns FileRegistry with the parsers of StrictRegistry in place of its own
+func strictParsers() map[string]FileParser {
+	parsers := maps.Clone(FileRegistry)
+	maps.Copy(parsers, StrictRegistry)
+	return parsers
+}
+
+type keyKind int
+
+const (
+	keyScalar keyKind = iota
+	keyMapping
+	keySequence
+)
+
+// keyNode is the shape of a document : the keys of its mappings, duplicates included, and the items of its sequences
+type keyNode struct {
+	Key    string
+	Line   int
+	Column int
+	Kind   keyKind
+	Fields []*keyNode
+	Items  []*keyNode
+}
+
+// keyTrees build the keyNode of the formats with nested keys
+var keyTrees = map[string]func([]byte) (*keyNode, error){
+	"json": jsonKeyTree,
+	"yaml": yamlKeyTree,
+	"toml": tomlKeyTree,
+}
+
+// strictParser checks the keys of data against the type of v before parsing it with parser
+func strictParser(tree func([]byte) (*keyNode, error), tag string, parser FileParser) FileParser {
+	return func(data []byte, v any) error {
+		root, err := tree(data)
+		if err != nil {
+			return parser(data, v)
+		}
+		if err := checkKeys(root, reflect.TypeOf(v), tag); err != nil {
+			return err
+		}
+		return parser(data, v)
+	}
+}
+
+// strictChecker collects the unknown fields and the duplicate keys of an element
+type strictChecker struct {
+	tag     string
+	element int
+	errs    LoadErrors
+}
+
+// checkKeys checks a document decoded into typ, each item of a root sequence being an element.
+// A root sequence decoded into a struct, or the opposite, is left to the parser.
+func checkKeys(root *keyNode, typ reflect.Type, tag string) error {
+	typ = derefType(typ)
+	checker := &strictChecker{tag: tag}
+	switch {
+	case typ.Kind() == reflect.Slice && root.Kind == keySequence:
+		for i, item := range root.Items {
+			checker.element = i
+			checker.check(item, typ.Elem(), "")
+		}
+	case typ.Kind() == reflect.Slice || root.Kind == keySequence:
+		return nil
+	default:
+		checker.check(root, typ, "")
+	}
+
+	if len(checker.errs) > 0 {
+		return checker.errs
+	}
+	return nil
+}
+
+func (c *strictChecker) add(node *keyNode, pointer string, err error) {
+	c.errs = append(c.errs, &LoadError{
+		Element: c.element, Path: pointer, Line: node.Line, Column: node.Column,
+		Err: fmt.Errorf("%w %q", err, node.Key),
+	})
+}
+
+// check walks node decoded into typ, a nil type accepts any key
+func (c *strictChecker) check(node *keyNode, typ reflect.Type, pointer string) {
+	typ = derefType(typ)
+	if typ != nil && opaqueType(typ) {
+		typ = nil
+	}
+
+	switch node.Kind {
+	case keyMapping:
+		seen := make(map[string]bool)
+		for _, field := range node.Fields {
+			child := pointer + "/" + escapePointer(field.Key)
+			if seen[field.Key] {
+				c.add(field, child, ErrDuplicateKey)
+				continue
+			}
+			seen[field.Key] = true
+
+			var childType reflect.Type
+			if typ != nil && typ.Kind() == reflect.Struct {
+				structField, ok := lookupField(typ, field.Key, c.tag)
+				if !ok {
+					c.add(field, child, ErrUnknownField)
+					continue
+				}
+				childType = structField.Type
+			} else if typ != nil && typ.Kind() == reflect.Map {
+				childType = typ.Elem()
+			}
+			c.check(field, childType, child)
+		}
+	case keySequence:
+		var childType reflect.Type
+		if typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			childType = typ.Elem()
+		}
+		for i, item := range node.Items {
+			c.check(item, childType, pointer+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+// lookupField returns the field of typ named key in the format of tag, promoted fields included.
+// yaml names are case sensitive, json and toml names aren't.
+func lookupField(typ reflect.Type, key, tag string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if field.Anonymous && (name == "" || strings.Contains(options, "inline")) && derefType(field.Type).Kind() == reflect.Struct {
+			if promoted, ok := lookupField(derefType(field.Type), key, tag); ok {
+				return promoted, true
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name = fieldName(field, tag)
+		if name == key || (tag != "yaml" && strings.EqualFold(name, key)) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// opaqueType reports whether typ decodes itself, so its keys can't be checked
+func opaqueType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Interface || typ == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+	pointer := reflect.PointerTo(typ)
+	return pointer.Implements(reflect.TypeFor[json.Unmarshaler]()) ||
+		pointer.Implements(reflect.TypeFor[yaml.Unmarshaler]()) ||
+		pointer.Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
+func derefType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+func jsonKeyTree(data []byte) (*keyNode, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var walk func(node *keyNode) error
+	walk = func(node *keyNode) error {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := token.(json.Delim)
+		if !ok {
+			return nil
+		}
+
+		node.Kind = keySequence
+		if delim == '{' {
+			node.Kind = keyMapping
+		}
+		for decoder.More() {
+			child := &keyNode{}
+			child.Line, child.Column = offsetPosition(data, skipJSONSeparators(data, decoder.InputOffset()))
+			if delim == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				child.Key = fmt.Sprint(key)
+				node.Fields = append(node.Fields, child)
+			} else {
+				node.Items = append(node.Items, child)
+			}
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	}
+
+	root := &keyNode{}
+	root.Line, root.Column = offsetPosition(data, skipJSONSeparators(data, 0))
+	return root, walk(root)
+}
+
+func yamlKeyTree(data []byte) (*keyNode, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return &keyNode{}, nil
+	}
+
+	var walk func(node *yaml.Node) *keyNode
+	walk = func(node *yaml.Node) *keyNode {
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		result := &keyNode{Line: node.Line, Column: node.Column}
+		switch node.Kind {
+		case yaml.MappingNode:
+			result.Kind = keyMapping
+			var merged []*keyNode
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				child := walk(value)
+				if key.Tag == "!!merge" {
+					merged = append(merged, mergedFields(child)...)
+					continue
+				}
+				child.Key, child.Line, child.Column = key.Value, key.Line, key.Column
+				result.Fields = append(result.Fields, child)
+			}
+			// keys of merged mappings can be overridden
+			for _, field := range merged {
+				if !slices.ContainsFunc(result.Fields, func(own *keyNode) bool { return own.Key == field.Key }) {
+					result.Fields = append(result.Fields, field)
+				}
+			}
+		case yaml.SequenceNode:
+			result.Kind = keySequence
+			for _, item := range node.Content {
+				result.Items = append(result.Items, walk(item))
+			}
+		}
+		return result
+	}
+	return walk(document.Content[0]), nil
+}
+
+// mergedFields returns the fields merged with `<<`, from a mapping or a sequence of mappings
+func mergedFields(node *keyNode) []*keyNode {
+	if node.Kind == keyMapping {
+		return node.Fields
+	}
+	var fields []*keyNode
+	for _, item := range node.Items {
+		fields = append(fields, item.Fields...)
+	}
+	return fields
+}
+
+// tomlKeyTree builds the keys of a TOML document, the parser already rejects duplicate keys
+func tomlKeyTree(data []byte) (*keyNode, error) {
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var walk func(tree *toml.Tree) *keyNode
+	walk = func(tree *toml.Tree) *keyNode {
+		result := &keyNode{Kind: keyMapping, Line: tree.Position().Line, Column: tree.Position().Col}
+		for _, key := range tree.Keys() {
+			position := tree.GetPositionPath([]string{key})
+			child := &keyNode{Line: position.Line, Column: position.Col}
+			switch value := tree.GetPath([]string{key}).(type) {
+			case *toml.Tree:
+				child = walk(value)
+			case []*toml.Tree:
+				child.Kind = keySequence
+				for _, item := range value {
+					child.Items = append(child.Items, walk(item))
+				}
+			case []any:
+				child.Kind = keySequence
+				for range value {
+					child.Items = append(child.Items, &keyNode{Line: position.Line, Column: position.Col})
+				}
+			}
+			child.Key = key
+			if child.Line == 0 {
+				child.Line, child.Column = position.Line, position.Col
+			}
+			result.Fields = append(result.Fields, child)
+		}
+		return result
+	}
+	return walk(tree), nil
+}
+
+// strictXML rejects the elements and attributes without a field, and repeated elements of a non-slice field
+func strictXML(data []byte, v any) error {
+	typ := derefType(reflect.TypeOf(v))
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	checker := &strictChecker{tag: "xml"}
+	for {
+		line, column := decoder.InputPos()
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Unmarshal(data, v)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if err := checker.checkXML(decoder, start, typ, "", line, column); err != nil {
+				return xml.Unmarshal(data, v)
+			}
+			break
+		}
+	}
+
+	if len(checker.errs) > 0 {
+		return checker.errs
+	}
+	return xml.Unmarshal(data, v)
+}
+
+func (c *strictChecker) checkXML(decoder *xml.Decoder, start xml.StartElement, typ reflect.Type, pointer string, line, column int) error {
+	typ = derefType(typ)
+	if typ == nil || typ.Kind() != reflect.Struct || opaqueType(typ) {
+		return decoder.Skip()
+	}
+
+	elements := make(map[string]reflect.StructField)
+	attrs := make(map[string]bool)
+	anyElement, anyAttr := false, false
+	for _, field := range xmlFields(typ) {
+		name, options, _ := strings.Cut(field.Tag.Get("xml"), ",")
+		switch {
+		case strings.Contains(options, "innerxml"):
+			return decoder.Skip()
+		case strings.Contains(options, "any") && strings.Contains(options, "attr"):
+			anyAttr = true
+		case strings.Contains(options, "any"):
+			anyElement = true
+		case strings.Contains(options, "attr"):
+			attrs[fieldName(field, "xml")] = true
+		case strings.Contains(options, "chardata"), strings.Contains(options, "cdata"), strings.Contains(options, "comment"):
+		default:
+			if parent, _, nested := strings.Cut(name, ">"); nested {
+				// a>b fields are read from a child element
+				elements[parent] = reflect.StructField{Type: reflect.TypeOf((*any)(nil)).Elem()}
+				continue
+			}
+			elements[fieldName(field, "xml")] = field
+		}
+	}
+
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" || attrs[attr.Name.Local] || anyAttr {
+			continue
+		}
+		node := &keyNode{Key: attr.Name.Local, Line: line, Column: column}
+		c.add(node, pointer+"/"+escapePointer(attr.Name.Local), ErrUnknownField)
+	}
+
+	counts := make(map[string]int)
+	for {
+		line, column := decoder.InputPos()
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			name := element.Name.Local
+			node := &keyNode{Key: name, Line: line, Column: column}
+			child := pointer + "/" + escapePointer(name)
+			field, ok := elements[name]
+			if !ok {
+				if !anyElement {
+					c.add(node, child, ErrUnknownField)
+				}
+				if err := decoder.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+
+			childType := derefType(field.Type)
+			if childType.Kind() == reflect.Slice && childType.Elem().Kind() != reflect.Uint8 {
+				childType = childType.Elem()
+				child += "/" + strconv.Itoa(counts[name])
+			} else if counts[name] > 0 {
+				c.add(node, child, ErrDuplicateKey)
+			}
+			counts[name]++
+			if err := c.checkXML(decoder, element, childType, child, line, column); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// xmlFields returns the exported fields of typ mapped by encoding/xml, promoted fields included
+func xmlFields(typ reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Name == "XMLName" || field.Tag.Get("xml") == "-" {
+			continue
+		}
+		if field.Anonymous && field.Tag.Get("xml") == "" && derefType(field.Type).Kind() == reflect.Struct {
+			fields = append(fields, xmlFields(derefType(field.Type))...)
+			continue
+		}
+		if field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -1,13 +1,19 @@
 package simple
 
 import (
+    "os"
     "fmt"
+	"time"
 	"context"
+	"slices"
+	"net/url"
+	"strings"
 
     "gorm.io/gorm"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/sqlite"
 	"gorm.io/driver/postgres"
+	mysqldriver "github.com/go-sql-driver/mysql"
 )
 
 type FactoryDB func(dsn string) gorm.Dialector
@@ -122,6 +128,136 @@ func CountRows[T any](ctx context.Context, db *gorm.DB) (int64, error) {
 }
 
 
+// OpenDatabaseFromEnv opens a database from the environment, see DatabaseFromEnv.
+// Use a nil env to read the process environment.
+func OpenDatabaseFromEnv(env *Env) (*gorm.DB, error) {
+	driver, dsn, err := DatabaseFromEnv(env)
+	if err != nil {
+		return nil, err
+	}
+	return OpenDatabase(driver, dsn)
+}
+
+// DatabaseFromEnv returns the driver and the escaped DSN built from :
+// DATABASE_URL (postgres://, mysql:// or sqlite://file), or the PGHOST/PGPORT/PGUSER/PGPASSWORD/PGDATABASE/PGSSLMODE variables,
+// or the MYSQL_HOST/MYSQL_PORT/MYSQL_USER/MYSQL_PASSWORD/MYSQL_DATABASE variables.
+// Use a nil env to read the process environment.
+func DatabaseFromEnv(env *Env) (string, string, error) {
+	lookup := os.LookupEnv
+	if env != nil {
+		lookup = env.Lookup
+	}
+	get := func(key, fallback string) string {
+		if value, ok := lookup(key); ok && value != "" {
+			return value
+		}
+		return fallback
+	}
+
+	if databaseURL := get("DATABASE_URL", ""); databaseURL != "" {
+		return parseDatabaseURL(databaseURL)
+	}
+	if get("PGHOST", "") != "" || get("PGDATABASE", "") != "" {
+		return "postgres", postgresDSN(map[string]string{
+			"host":     get("PGHOST", "localhost"),
+			"port":     get("PGPORT", "5432"),
+			"user":     get("PGUSER", ""),
+			"password": get("PGPASSWORD", ""),
+			"dbname":   get("PGDATABASE", ""),
+			"sslmode":  get("PGSSLMODE", ""),
+		}), nil
+	}
+	if get("MYSQL_HOST", "") != "" {
+		return "mysql", mysqlDSN(get("MYSQL_USER", ""), get("MYSQL_PASSWORD", ""), get("MYSQL_HOST", ""), get("MYSQL_PORT", "3306"), get("MYSQL_DATABASE", ""), nil), nil
+	}
+	return "", "", fmt.Errorf("%w: DATABASE_URL, PGHOST or MYSQL_HOST", ErrEnvKeyMissing)
+}
+
+// parseDatabaseURL converts a database URL into the DSN of its driver
+func parseDatabaseURL(databaseURL string) (string, string, error) {
+	scheme, rest, ok := strings.Cut(databaseURL, "://")
+	if !ok {
+		return "", "", fmt.Errorf("invalid DATABASE_URL: missing scheme")
+	}
+	if strings.ToLower(scheme) == "sqlite" || strings.ToLower(scheme) == "sqlite3" {
+		return "sqlite", rest, nil
+	}
+
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid DATABASE_URL: %w", err)
+	}
+	password, _ := u.User.Password()
+	dbName := strings.TrimPrefix(u.Path, "/")
+
+	switch strings.ToLower(u.Scheme) {
+	case "postgres", "postgresql":
+		params := map[string]string{
+			"host":     u.Hostname(),
+			"port":     u.Port(),
+			"user":     u.User.Username(),
+			"password": password,
+			"dbname":   dbName,
+		}
+		for key, values := range u.Query() {
+			params[key] = values[len(values)-1]
+		}
+		return "postgres", postgresDSN(params), nil
+	case "mysql":
+		port := u.Port()
+		if port == "" {
+			port = "3306"
+		}
+		return "mysql", mysqlDSN(u.User.Username(), password, u.Hostname(), port, dbName, u.Query()), nil
+	default:
+		return "", "", fmt.Errorf("unsupported driver: %s", u.Scheme)
+	}
+}
+
+// postgresDSN builds a keyword/value DSN, values are quoted when needed. Empty values are left out.
+func postgresDSN(params map[string]string) string {
+	order := []string{"host", "port", "user", "password", "dbname", "sslmode"}
+	keys := slices.Clone(order)
+	var extra []string
+	for key := range params {
+		if !slices.Contains(order, key) {
+			extra = append(extra, key)
+		}
+	}
+	slices.Sort(extra)
+	keys = append(keys, extra...)
+
+	var parts []string
+	for _, key := range keys {
+		value := params[key]
+		if value == "" {
+			continue
+		}
+		if strings.ContainsAny(value, " '\\") {
+			value = "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// mysqlDSN builds a go-sql-driver DSN with the same defaults as GetMysql()
+func mysqlDSN(user, password, host, port, dbName string, query url.Values) string {
+	config := mysqldriver.NewConfig()
+	config.User = user
+	config.Passwd = password
+	config.Net = "tcp"
+	config.Addr = host + ":" + port
+	config.DBName = dbName
+	config.ParseTime = true
+	config.Loc = time.Local
+	config.Params = map[string]string{"charset": "utf8mb4"}
+	for key, values := range query {
+		config.Params[key] = values[len(values)-1]
+	}
+	return config.FormatDSN()
+}
+
 func GetMysql(user string, password string, host string, port string, dbName string) (string, string) {
 	return "mysql", fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local", user, password, host, port, dbName)
 }
@@ -0,0 +1,135 @@
+package simple
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret is a string that never shows its value when printed, marshaled or logged.
+// Every format of SaveFile writes it redacted, so a Secret can't be persisted : store a reference such as
+// `file:/run/secrets/db_password` instead. Use Reveal() to read the real value.
+type Secret string
+
+var (
+	_ fmt.Stringer           = Secret("")
+	_ fmt.Formatter          = Secret("")
+	_ json.Marshaler         = Secret("")
+	_ encoding.TextMarshaler = Secret("")
+	_ slog.LogValuer         = Secret("")
+)
+
+// Reveal returns the real value of the secret
+func (s Secret) Reveal() string { return string(s) }
+
+func (s Secret) String() string   { return redacted }
+func (s Secret) GoString() string { return "simple.Secret(" + strconv.Quote(redacted) + ")" }
+
+// Format redacts every fmt verb
+func (s Secret) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'q':
+		fmt.Fprint(f, strconv.Quote(redacted))
+	case 'v':
+		if f.Flag('#') {
+			fmt.Fprint(f, s.GoString())
+			return
+		}
+		fmt.Fprint(f, redacted)
+	default:
+		fmt.Fprint(f, redacted)
+	}
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) { return json.Marshal(redacted) }
+func (s Secret) MarshalYAML() (any, error)    { return redacted, nil }
+func (s Secret) MarshalText() ([]byte, error) { return []byte(redacted), nil } // used by toml, xml and csv
+func (s Secret) LogValue() slog.Value         { return slog.StringValue(redacted) }
+
+// isSecretField reports whether a field holds a Secret or has the `secret:"true"` tag
+func isSecretField(field reflect.StructField) bool {
+	typ := field.Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeOf(Secret("")) {
+		return true
+	}
+	secret, _ := strconv.ParseBool(field.Tag.Get("secret"))
+	return secret
+}
+
+// RedactedDump returns one `Field.Path = value` line per field of a config struct, for startup logging.
+// Secret fields and fields with the `secret:"true"` tag are redacted.
+func RedactedDump(config any) string {
+	var lines []string
+	dumpValue(reflect.ValueOf(config), "", false, &lines)
+	return strings.Join(lines, "\n")
+}
+
+func dumpValue(value reflect.Value, path string, secret bool, lines *[]string) {
+	if secret {
+		*lines = append(*lines, path+" = "+redacted)
+		return
+	}
+	if !value.IsValid() {
+		*lines = append(*lines, path+" = <nil>")
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			*lines = append(*lines, path+" = <nil>")
+			return
+		}
+		dumpValue(value.Elem(), path, false, lines)
+		return
+	case reflect.Struct:
+		if !isEnvStruct(value.Type()) {
+			break
+		}
+		typ := value.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			dumpValue(value.Field(i), fieldPath, isSecretField(field), lines)
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		for i := 0; i < value.Len(); i++ {
+			dumpValue(value.Index(i), fmt.Sprintf("%s[%d]", path, i), false, lines)
+		}
+		return
+	case reflect.Map:
+		for _, key := range sortedMapKeys(value) {
+			dumpValue(value.MapIndex(key), fmt.Sprintf("%s[%v]", path, key), false, lines)
+		}
+		return
+	}
+	*lines = append(*lines, path+" = "+fmt.Sprintf("%v", value.Interface()))
+}
+
+func sortedMapKeys(value reflect.Value) []reflect.Value {
+	keys := value.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+	})
+	return keys
+}
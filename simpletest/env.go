@@ -0,0 +1,55 @@
+// Package simpletest provides test helpers for the simple package
+package simpletest
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// EnvSandbox snapshots the process environment and restores it entirely on t.Cleanup,
+// including the variables set or removed by the code under test.
+// The process environment is global : tests using a sandbox must not call t.Parallel(),
+// use LoadEnv() for parallel tests.
+func EnvSandbox(t testing.TB) {
+	t.Helper()
+	snapshot := os.Environ()
+	t.Cleanup(func() {
+		os.Clearenv()
+		for _, variable := range snapshot {
+			if key, value, ok := strings.Cut(variable, "="); ok {
+				os.Setenv(key, value)
+			}
+		}
+	})
+}
+
+// SetEnv applies values to the process environment for the duration of the test
+func SetEnv(t testing.TB, values map[string]string) {
+	t.Helper()
+	EnvSandbox(t)
+	for key, value := range values {
+		if err := os.Setenv(key, value); err != nil {
+			t.Fatalf("couldn't set %s: %v", key, err)
+		}
+	}
+}
+
+// SetEnvString applies an in-memory .env content to the process environment for the duration of the test
+func SetEnvString(t testing.TB, content string) {
+	t.Helper()
+	SetEnv(t, LoadEnv(t, content).Map())
+}
+
+// LoadEnv parses an in-memory .env content without touching the disk nor the process environment,
+// it is safe for parallel tests
+func LoadEnv(t testing.TB, content string) *simple.Env {
+	t.Helper()
+	env, err := simple.LoadEnvSources(simple.EnvStringSource(t.Name(), content))
+	if err != nil {
+		t.Fatalf("couldn't parse env content: %v", err)
+	}
+	return env
+}
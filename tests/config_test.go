@@ -0,0 +1,111 @@
+package test
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bl4omArchie/simple"
+	"github.com/Bl4omArchie/simple/simpletest"
+)
+
+type AppConfig struct {
+	Name    string        `yaml:"name" validate:"required"`
+	Debug   bool          `yaml:"debug"`
+	Timeout time.Duration `yaml:"timeout" default:"5s"`
+	DB      struct {
+		Host     string `yaml:"host" default:"localhost"`
+		MaxConns int    `yaml:"max_conns" default:"10" validate:"gt=0"`
+		Password string `yaml:"password"`
+	} `yaml:"db"`
+}
+
+// Test the precedence and the origin of every layer
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("name: simple\ndb:\n  host: db.local\n  max_conns: 20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env := simpletest.LoadEnv(t, "APP_DB__MAX_CONNS=30\nAPP_DB__PASSWORD=secret\n")
+
+	config, origins, err := simple.LoadConfig[AppConfig](path, env, "APP_", []string{"-db.max-conns=40", "-debug"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Name != "simple" || !config.Debug || config.Timeout != 5*time.Second {
+		t.Errorf("unexpected values: %+v", config)
+	}
+	if config.DB.Host != "db.local" || config.DB.MaxConns != 40 || config.DB.Password != "secret" {
+		t.Errorf("unexpected db values: %+v", config.DB)
+	}
+
+	want := map[string]simple.ConfigOrigin{
+		"Name":        simple.OriginFile,
+		"Debug":       simple.OriginFlag,
+		"Timeout":     simple.OriginDefault,
+		"DB.Host":     simple.OriginFile,
+		"DB.MaxConns": simple.OriginFlag,
+		"DB.Password": simple.OriginEnv,
+	}
+	for field, origin := range want {
+		if origins[field] != origin {
+			t.Errorf("%s: got origin %q, wanted %q", field, origins[field], origin)
+		}
+	}
+}
+
+// Test that the merged config is validated
+func TestLoadConfigValidation(t *testing.T) {
+	env := simpletest.LoadEnv(t, "APP_DB__MAX_CONNS=0\n")
+	if _, _, err := simple.LoadConfig[AppConfig]("", env, "APP_", []string{"-name=simple"}); err == nil {
+		t.Errorf("expected a validation error for DB.MaxConns")
+	}
+}
+
+type PinConfig struct {
+	Pin int `secret:"true"`
+}
+
+// Test that env errors redact secret values and that -h gives flag.ErrHelp
+func TestLoadConfigErrors(t *testing.T) {
+	env := simpletest.LoadEnv(t, "APP_PIN=12x4\n")
+	_, _, err := simple.LoadConfig[PinConfig]("", env, "APP_", nil)
+	var envErr *simple.EnvError
+	if !errors.As(err, &envErr) || len(envErr.Malformed) != 1 || strings.Contains(err.Error(), "12x4") {
+		t.Errorf("expected a malformed error without the secret value, got %v", err)
+	}
+
+	_, _, err = simple.LoadConfig[PinConfig]("", simpletest.LoadEnv(t, ""), "APP_", []string{"-h"})
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected flag.ErrHelp, got %v", err)
+	}
+}
+
+// Test that a key of the file is its origin even when it repeats the default value
+func TestLoadConfigFileOrigins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"name": "simple", "db": {"host": "localhost"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, origins, err := simple.LoadConfig[AppConfig](path, simpletest.LoadEnv(t, ""), "APP_", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]simple.ConfigOrigin{
+		"Name":        simple.OriginFile,
+		"Timeout":     simple.OriginDefault,
+		"DB.Host":     simple.OriginFile,
+		"DB.MaxConns": simple.OriginDefault,
+	}
+	for field, origin := range want {
+		if origins[field] != origin {
+			t.Errorf("%s: got origin %q, wanted %q", field, origins[field], origin)
+		}
+	}
+}
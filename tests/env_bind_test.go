@@ -0,0 +1,64 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+type DatabaseEnv struct {
+	Host string `env:"HOST" default:"localhost"`
+	Port int    `env:"PORT" default:"5432" validate:"gt=0"`
+}
+
+type ServiceEnv struct {
+	Name     string        `env:"NAME" required:"true"`
+	Debug    bool          `env:"DEBUG"`
+	Timeout  time.Duration `env:"TIMEOUT" default:"5s"`
+	Tags     []string      `env:"TAGS"`
+	Database DatabaseEnv   `envPrefix:"DB_"`
+}
+
+// Test LoadEnvInto with prefix, defaults and nested structs
+func TestLoadEnvInto(t *testing.T) {
+	t.Setenv("APP_NAME", "simple")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("APP_TAGS", "a, b,c")
+	t.Setenv("APP_DB_HOST", "db.local")
+
+	got, err := simple.LoadEnvInto[ServiceEnv]("APP_", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Name != "simple" || !got.Debug || got.Timeout != 5*time.Second {
+		t.Errorf("unexpected values: %+v", got)
+	}
+	if len(got.Tags) != 3 || got.Tags[2] != "c" {
+		t.Errorf("got tags %v, wanted [a b c]", got.Tags)
+	}
+	if got.Database.Host != "db.local" || got.Database.Port != 5432 {
+		t.Errorf("unexpected database values: %+v", got.Database)
+	}
+}
+
+// Test that every missing and malformed variable is reported at once
+func TestLoadEnvIntoErrors(t *testing.T) {
+	t.Setenv("BAD_DEBUG", "maybe")
+	t.Setenv("BAD_DB_PORT", "abc")
+
+	_, err := simple.LoadEnvInto[ServiceEnv]("BAD_", false)
+
+	var envErr *simple.EnvError
+	if !errors.As(err, &envErr) {
+		t.Fatalf("expected *simple.EnvError, got %v", err)
+	}
+	if len(envErr.Missing) != 1 || envErr.Missing[0] != "BAD_NAME" {
+		t.Errorf("got missing %v, wanted [BAD_NAME]", envErr.Missing)
+	}
+	if len(envErr.Malformed) != 2 {
+		t.Errorf("got %d malformed variables, wanted 2", len(envErr.Malformed))
+	}
+}
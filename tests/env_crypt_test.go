@@ -0,0 +1,179 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// Test an encryption round trip with a key file
+func TestEncryptEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, ".env")
+	encrypted := filepath.Join(dir, ".env.enc")
+	keyFile := filepath.Join(dir, "env.key")
+
+	content := "# database\nDB_USER=admin\nexport DB_PASS=\"pa ss\"\n\nLITERAL='${DB_USER}'\n"
+	if err := os.WriteFile(plain, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := simple.GenerateEnvKeyFile(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	key, err := simple.EnvKeyFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := simple.EncryptEnvFile(plain, encrypted, key); err != nil {
+		t.Fatalf("couldn't encrypt: %v", err)
+	}
+	data, err := os.ReadFile(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "admin") || !strings.Contains(string(data), "# database") {
+		t.Errorf("unexpected encrypted content:\n%s", data)
+	}
+
+	env, err := simple.LoadEnvSources(simple.EnvEncryptedFileSource(encrypted, false, key))
+	if err != nil {
+		t.Fatalf("couldn't load encrypted file: %v", err)
+	}
+	if err := env.Expand(); err != nil {
+		t.Fatal(err)
+	}
+	if env.Get("DB_USER") != "admin" || env.Get("DB_PASS") != "pa ss" || env.Get("LITERAL") != "${DB_USER}" {
+		t.Errorf("unexpected values: %v", env.Map())
+	}
+
+	decrypted := filepath.Join(dir, ".env.dec")
+	if err := simple.DecryptEnvFile(encrypted, decrypted, key); err != nil {
+		t.Fatalf("couldn't decrypt: %v", err)
+	}
+	if got, _ := os.ReadFile(decrypted); string(got) != content {
+		t.Errorf("got %q, wanted %q", got, content)
+	}
+
+	// Unchanged values keep their ciphertext
+	if err := simple.EncryptEnvFile(plain, encrypted, key); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadFile(encrypted); string(again) != string(data) {
+		t.Errorf("re-encryption changed unchanged values")
+	}
+}
+
+// Test that a modified ciphertext or a wrong passphrase is rejected
+func TestDecryptEnvFileTampered(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, ".env")
+	encrypted := filepath.Join(dir, ".env.enc")
+	if err := os.WriteFile(plain, []byte("TOKEN=abc\nOTHER=def\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := simple.EncryptEnvFile(plain, encrypted, simple.EnvPassphrase("correct horse")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := simple.LoadEnvSources(simple.EnvEncryptedFileSource(encrypted, false, simple.EnvPassphrase("wrong")))
+	if !errors.Is(err, simple.ErrEnvTampered) {
+		t.Errorf("expected ErrEnvTampered with a wrong passphrase, got %v", err)
+	}
+
+	// Swap two ciphertexts, each value is bound to its key
+	data, _ := os.ReadFile(encrypted)
+	lines := strings.Split(string(data), "\n")
+	token := strings.SplitN(lines[1], "=", 2)[1]
+	other := strings.SplitN(lines[2], "=", 2)[1]
+	lines[1], lines[2] = "TOKEN="+other, "OTHER="+token
+	if err := os.WriteFile(encrypted, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = simple.LoadEnvSources(simple.EnvEncryptedFileSource(encrypted, false, simple.EnvPassphrase("correct horse")))
+	if !errors.Is(err, simple.ErrEnvTampered) {
+		t.Errorf("expected ErrEnvTampered for swapped values, got %v", err)
+	}
+
+	// Delete a line, the header authenticates the list of entries
+	lines[1], lines[2] = "TOKEN="+token, "OTHER="+other
+	deleted := append([]string{lines[0]}, lines[2:]...)
+	if err := os.WriteFile(encrypted, []byte(strings.Join(deleted, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = simple.LoadEnvSources(simple.EnvEncryptedFileSource(encrypted, false, simple.EnvPassphrase("correct horse")))
+	if !errors.Is(err, simple.ErrEnvTampered) {
+		t.Errorf("expected ErrEnvTampered for a deleted line, got %v", err)
+	}
+
+	// A value copied from another file with the same key is bound to its salt
+	keyFile := filepath.Join(dir, "env.key")
+	if err := simple.GenerateEnvKeyFile(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	key, err := simple.EnvKeyFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := filepath.Join(dir, "first.enc"), filepath.Join(dir, "second.enc")
+	for _, dst := range []string{first, second} {
+		if err := simple.EncryptEnvFile(plain, dst, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	firstData, _ := os.ReadFile(first)
+	secondData, _ := os.ReadFile(second)
+	firstLines, secondLines := strings.Split(string(firstData), "\n"), strings.Split(string(secondData), "\n")
+	secondLines[1] = firstLines[1]
+	if err := os.WriteFile(second, []byte(strings.Join(secondLines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = simple.LoadEnvSources(simple.EnvEncryptedFileSource(second, false, key))
+	if !errors.Is(err, simple.ErrEnvTampered) {
+		t.Errorf("expected ErrEnvTampered for a copied value, got %v", err)
+	}
+}
+
+// Test that LoadEnvProfile and ReadEnv decrypt .env.enc files with the key of the environment
+func TestLoadEnvEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(t.TempDir(), ".env.staging")
+	encrypted := filepath.Join(dir, ".env.staging.enc")
+	if err := os.WriteFile(plain, []byte("SIMPLE_TEST_DB_PASS=secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("SIMPLE_TEST_DB_USER=admin\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := simple.EncryptEnvFile(plain, encrypted, simple.EnvPassphrase("correct horse")); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SIMPLE_TEST_PROFILE", "staging")
+	t.Setenv(simple.EnvKeyFileVariable, "")
+	t.Setenv(simple.EnvPassphraseVariable, "")
+	if _, err := simple.LoadEnvProfile(dir, "SIMPLE_TEST_PROFILE"); !errors.Is(err, simple.ErrEnvKeyMissing) {
+		t.Errorf("expected a missing key error, got %v", err)
+	}
+
+	t.Setenv(simple.EnvPassphraseVariable, "correct horse")
+	env, err := simple.LoadEnvProfile(dir, "SIMPLE_TEST_PROFILE")
+	if err != nil {
+		t.Fatalf("couldn't load profile: %v", err)
+	}
+	if env.Get("SIMPLE_TEST_DB_PASS") != "secret" || env.Get("SIMPLE_TEST_DB_USER") != "admin" {
+		t.Errorf("unexpected values: %v", env.Map())
+	}
+	if origin := env.Origin("SIMPLE_TEST_DB_PASS"); origin != encrypted {
+		t.Errorf("got origin %s, wanted %s", origin, encrypted)
+	}
+
+	values, err := simple.ReadEnv([]string{encrypted}, false)
+	if err != nil || values["SIMPLE_TEST_DB_PASS"] != "secret" {
+		t.Errorf("ReadEnv: got %v, %v", values, err)
+	}
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// Test that editing a document keeps comments, ordering and quoting style
+func TestEnvDocument(t *testing.T) {
+	content := "# Database\nexport DB_HOST=localhost # local only\n\nDB_PASS='old'\nTOKEN=\"multi\nline\"\nOLD_NAME=x\n"
+	doc, err := simple.ParseEnvDocument(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var unchanged strings.Builder
+	if _, err := doc.WriteTo(&unchanged); err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.String() != content {
+		t.Errorf("round trip changed the document:\n%s", unchanged.String())
+	}
+
+	if value, ok := doc.Get("TOKEN"); !ok || value != "multi\nline" {
+		t.Errorf("got %q, wanted multi\\nline", value)
+	}
+
+	for key, value := range map[string]string{"DB_HOST": "db.example.com", "DB_PASS": "new secret", "TOKEN": "rotated"} {
+		if err := doc.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := doc.Set("NEW_KEY", "with space"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Set("A B", "x"); err == nil {
+		t.Errorf("expected an error for an invalid key")
+	}
+	if err := doc.Rename("OLD_NAME", "NEW_NAME"); err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Delete("NEW_NAME") {
+		t.Errorf("couldn't delete NEW_NAME")
+	}
+
+	want := "# Database\nexport DB_HOST=db.example.com # local only\n\nDB_PASS='new secret'\nTOKEN=\"rotated\"\nNEW_KEY=\"with space\"\n"
+	var got strings.Builder
+	doc.WriteTo(&got)
+	if got.String() != want {
+		t.Errorf("got:\n%s\nwanted:\n%s", got.String(), want)
+	}
+}
+
+// Test that renaming a key keeps its export prefix
+func TestEnvDocumentRenameExport(t *testing.T) {
+	doc, err := simple.ParseEnvDocument(strings.NewReader("export port=1\n  export   host = x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Rename("port", "PORT"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Rename("host", "HOST"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Rename("HOST", "A B"); err == nil {
+		t.Errorf("expected an error for an invalid key")
+	}
+
+	var got strings.Builder
+	doc.WriteTo(&got)
+	if want := "export PORT=1\n  export   HOST = x\n"; got.String() != want {
+		t.Errorf("got:\n%s\nwanted:\n%s", got.String(), want)
+	}
+}
+
+// Test Save with an atomic write keeping file permissions
+func TestEnvDocumentSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("TOKEN=abc\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := simple.OpenEnvDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Set("TOKEN", "def"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Save(path); err != nil {
+		t.Fatalf("couldn't save: %v", err)
+	}
+
+	values, err := simple.ReadEnv([]string{path}, false)
+	if err != nil || values["TOKEN"] != "def" {
+		t.Errorf("got %v, %v, wanted def", values, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("got permissions %v, wanted 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+}
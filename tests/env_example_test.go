@@ -0,0 +1,69 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+type ExampleEnv struct {
+	Host     string      `env:"HOST" default:"localhost" description:"Server host"`
+	Password string      `env:"PASSWORD" required:"true" description:"Database password"`
+	Legacy   string      `env:"LEGACY" deprecated:"use HOST"`
+	Database DatabaseEnv `envPrefix:"DB_"`
+}
+
+// Test the generated .env.example
+func TestEnvExample(t *testing.T) {
+	want := "# Generated from test.ExampleEnv\n" +
+		"\n# Server host\nAPP_HOST=localhost\n" +
+		"\n# Database password (required)\nAPP_PASSWORD=\n" +
+		"\nAPP_DB_HOST=localhost\n" +
+		"\nAPP_DB_PORT=5432\n"
+
+	if got := simple.EnvExample[ExampleEnv]("APP_"); got != want {
+		t.Errorf("got:\n%s\nwanted:\n%s", got, want)
+	}
+}
+
+// Test drift detection between a .env file and a struct
+func TestCheckEnvDrift(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("APP_HOST=h\nAPP_LEGACY=x\nAPP_TYPO=y\nAPP_DB_PORT=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	drift, err := simple.CheckEnvDrift[ExampleEnv](path, "APP_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !drift.HasDrift() {
+		t.Fatalf("expected a drift")
+	}
+	if !slices.Equal(drift.Unknown, []string{"APP_TYPO"}) {
+		t.Errorf("got unknown %v", drift.Unknown)
+	}
+	if !slices.Equal(drift.Missing, []string{"APP_PASSWORD", "APP_DB_HOST"}) {
+		t.Errorf("got missing %v", drift.Missing)
+	}
+	if !slices.Equal(drift.MissingRequired, []string{"APP_PASSWORD"}) {
+		t.Errorf("got missing required %v", drift.MissingRequired)
+	}
+	if !slices.Equal(drift.Deprecated, []string{"APP_LEGACY"}) {
+		t.Errorf("got deprecated %v", drift.Deprecated)
+	}
+	// Optional variables and defaults may be absent
+	if err := os.WriteFile(path, []byte("APP_PASSWORD=p\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	drift, err = simple.CheckEnvDrift[ExampleEnv](path, "APP_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drift.HasDrift() || len(drift.Missing) == 0 {
+		t.Errorf("expected no drift, got %s with missing %v", drift, drift.Missing)
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bl4omArchie/simple"
+	"github.com/Bl4omArchie/simple/simpletest"
+)
+
+// Test every typed getter on valid values
+func TestEnvGetters(t *testing.T) {
+	env := simpletest.LoadEnv(t, `
+TIMEOUT=1m30s
+CACHE=512MiB
+UPLOAD=1.5GB
+HOSTS=a, b,,c
+LABELS=region=eu, tier=gold
+API=https://example.com/v1
+BIND=::1
+NETWORK=10.0.0.0/8
+TZ=Europe/Paris
+MODE=prod
+`)
+
+	if d, err := env.Duration("TIMEOUT"); err != nil || d != 90*time.Second {
+		t.Errorf("Duration: got %v, %v", d, err)
+	}
+	if size, err := env.ByteSize("CACHE"); err != nil || size != 512<<20 {
+		t.Errorf("ByteSize: got %v, %v", size, err)
+	}
+	if size, err := env.ByteSize("UPLOAD"); err != nil || size != 1500000000 {
+		t.Errorf("ByteSize: got %v, %v", size, err)
+	}
+	if hosts, err := env.List("HOSTS"); err != nil || strings.Join(hosts, "|") != "a|b|c" {
+		t.Errorf("List: got %v, %v", hosts, err)
+	}
+	if labels, err := env.StringMap("LABELS"); err != nil || labels["region"] != "eu" || labels["tier"] != "gold" {
+		t.Errorf("StringMap: got %v, %v", labels, err)
+	}
+	if u, err := env.URL("API"); err != nil || u.Host != "example.com" {
+		t.Errorf("URL: got %v, %v", u, err)
+	}
+	if ip, err := env.IP("BIND"); err != nil || !ip.IsLoopback() {
+		t.Errorf("IP: got %v, %v", ip, err)
+	}
+	if network, err := env.CIDR("NETWORK"); err != nil || network.String() != "10.0.0.0/8" {
+		t.Errorf("CIDR: got %v, %v", network, err)
+	}
+	if location, err := env.Location("TZ"); err != nil || location.String() != "Europe/Paris" {
+		t.Errorf("Location: got %v, %v", location, err)
+	}
+	if mode, err := env.Enum("MODE", "dev", "prod"); err != nil || mode != "prod" {
+		t.Errorf("Enum: got %v, %v", mode, err)
+	}
+}
+
+// Test that errors name the variable and its raw value
+func TestEnvGettersErrors(t *testing.T) {
+	env := simpletest.LoadEnv(t, "CACHE=12XB\nMODE=test\nMAX=9223372036854775807\nHUGE=9223372036854775808\nBIG=9000000TiB\nFLOAT=9223372036854775807.0\n")
+
+	_, err := env.ByteSize("CACHE")
+	var varErr simple.EnvVarError
+	if !errors.As(err, &varErr) || varErr.Name != "CACHE" || varErr.Value != "12XB" {
+		t.Errorf("got %v", err)
+	}
+	if size, err := env.ByteSize("MAX"); err != nil || size != 9223372036854775807 {
+		t.Errorf("ByteSize: got %v, %v", size, err)
+	}
+	for _, key := range []string{"HUGE", "BIG", "FLOAT"} {
+		if size, err := env.ByteSize(key); err == nil {
+			t.Errorf("%s: expected an overflow error, got %v", key, size)
+		}
+	}
+	if _, err := env.Enum("MODE", "dev", "prod"); err == nil || !strings.Contains(err.Error(), `MODE="test"`) {
+		t.Errorf("got %v", err)
+	}
+	if _, err := env.Duration("MISSING"); !errors.Is(err, simple.ErrEnvKeyMissing) {
+		t.Errorf("expected ErrEnvKeyMissing, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// Test every lint rule with its position
+func TestLintEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	content := "DB_HOST=localhost\n" +
+		"1INVALID=x\n" +
+		"DB_PASS=abc#123\n" +
+		"NAME=simple  \n" +
+		"QUOTED=\"open\n" +
+		"PATH=/usr/bin\n"
+	if err := os.WriteFile(base, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(local, []byte("# override\nDB_HOST=db\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", os.Getenv("PATH"))
+
+	diagnostics, err := simple.LintEnvFiles(base, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		file   string
+		line   int
+		column int
+		rule   string
+	}{
+		{base, 5, 8, "syntax"},
+		{base, 2, 1, "key-name"},
+		{base, 3, 12, "unquoted-hash"},
+		{base, 4, 12, "trailing-whitespace"},
+		{base, 6, 1, "shadow-os"},
+		{local, 2, 1, "duplicate-key"},
+	}
+	if len(diagnostics) != len(want) {
+		t.Fatalf("got %d diagnostics, wanted %d: %v", len(diagnostics), len(want), diagnostics)
+	}
+	for i, w := range want {
+		d := diagnostics[i]
+		if d.File != w.file || d.Line != w.line || d.Column != w.column || d.Rule != w.rule {
+			t.Errorf("got %s, wanted %s:%d:%d %s", d, w.file, w.line, w.column, w.rule)
+		}
+	}
+
+	if err := simple.LintErrors(diagnostics, simple.LintError); err == nil {
+		t.Errorf("expected errors")
+	}
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// Test built-in and custom resolvers
+func TestResolveEnv(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "db_password")
+	if err := os.WriteFile(secret, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SIMPLE_TEST_TOKEN", "token")
+
+	simple.EnvResolverRegistry["upper"] = func(reference string) (string, error) {
+		return strings.ToUpper(reference), nil
+	}
+	defer delete(simple.EnvResolverRegistry, "upper")
+
+	got, err := simple.ResolveEnv(map[string]string{
+		"DB_PASSWORD": "file:" + secret,
+		"API_KEY":     "base64:aGVsbG8=",
+		"TOKEN":       "env:SIMPLE_TEST_TOKEN",
+		"NAME":        "upper:simple",
+		"URL":         "https://example.com",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"DB_PASSWORD": "s3cr3t",
+		"API_KEY":     "hello",
+		"TOKEN":       "token",
+		"NAME":        "SIMPLE",
+		"URL":         "https://example.com",
+	}
+	for key, w := range want {
+		if got[key] != w {
+			t.Errorf("%s: got %q, wanted %q", key, got[key], w)
+		}
+	}
+
+	if _, err := simple.ResolveEnv(map[string]string{"BAD": "base64:!!"}); err == nil {
+		t.Errorf("expected an error for an invalid base64 value")
+	}
+}
+
+type ResolvedConfig struct {
+	Password string `env:"DB_PASSWORD" secret:"true"`
+	APIKey   string `env:"API_KEY"`
+}
+
+// Test that the loaders resolve the references of the files and the environment
+func TestResolveEnvLoaders(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db_password")
+	if err := os.WriteFile(secret, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte("DB_PASSWORD=file:"+secret+"\nAPI_KEY=base64:aGVsbG8=\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := simple.ReadEnv([]string{path}, false)
+	if err != nil || values["DB_PASSWORD"] != "s3cr3t" || values["API_KEY"] != "hello" {
+		t.Errorf("ReadEnv: got %v, %v", values, err)
+	}
+
+	env, err := simple.LoadEnvProfile(dir, "SIMPLE_TEST_PROFILE")
+	if err != nil || env.Get("DB_PASSWORD") != "s3cr3t" {
+		t.Fatalf("LoadEnvProfile: got %q, %v", env.Get("DB_PASSWORD"), err)
+	}
+	if config, err := simple.BindEnv[ResolvedConfig](env, "", false); err != nil || config.APIKey != "hello" {
+		t.Errorf("BindEnv: got %+v, %v", config, err)
+	}
+
+	env, err = simple.LoadEnvSources(simple.EnvFileSource(path, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config, err := simple.BindEnv[ResolvedConfig](env, "", false); err != nil || config.Password != "s3cr3t" {
+		t.Errorf("BindEnv without Resolve: got %+v, %v", config, err)
+	}
+
+	t.Setenv("SIMPLE_RESOLVE_DB_PASSWORD", "file:"+secret)
+	t.Setenv("SIMPLE_RESOLVE_API_KEY", "base64:aGVsbG8=")
+	if config, err := simple.LoadEnvInto[ResolvedConfig]("SIMPLE_RESOLVE_", false); err != nil || config.Password != "s3cr3t" || config.APIKey != "hello" {
+		t.Errorf("LoadEnvInto: got %+v, %v", config, err)
+	}
+
+	t.Setenv("SIMPLE_RESOLVE_DB_PASSWORD", "base64:!!")
+	_, err = simple.LoadEnvInto[ResolvedConfig]("SIMPLE_RESOLVE_", false)
+	var envErr *simple.EnvError
+	if !errors.As(err, &envErr) || len(envErr.Malformed) != 1 || strings.Contains(err.Error(), "!!") {
+		t.Errorf("expected a redacted resolution error, got %v", err)
+	}
+}
+
+// Test that unrelated process variables using a scheme are kept as is
+func TestResolveEnvKeepsOSValues(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("API_KEY=base64:aGVsbG8=\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SIMPLE_TEST_SQLITE_URL", "file:app.db?cache=shared")
+
+	env, err := simple.LoadEnvProfile(dir, "SIMPLE_TEST_PROFILE")
+	if err != nil {
+		t.Fatalf("LoadEnvProfile: unexpected error: %v", err)
+	}
+	if env.Get("SIMPLE_TEST_SQLITE_URL") != "file:app.db?cache=shared" || env.Get("API_KEY") != "hello" {
+		t.Errorf("unexpected values %q, %q", env.Get("SIMPLE_TEST_SQLITE_URL"), env.Get("API_KEY"))
+	}
+
+	values, err := simple.ReadEnv([]string{filepath.Join(dir, ".env")}, false, "API_KEY", "SIMPLE_TEST_SQLITE_URL")
+	if err != nil || values["SIMPLE_TEST_SQLITE_URL"] != "file:app.db?cache=shared" || values["API_KEY"] != "hello" {
+		t.Errorf("ReadEnv: got %v, %v", values, err)
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// Test layering order and origins of LoadEnvSources
+func TestLoadEnvSources(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	if err := os.WriteFile(base, []byte("DB_HOST=base\nDB_PORT=5432\nLAYER_ONLY_KEY=base\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(local, []byte("DB_HOST=local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_PORT", "6543")
+
+	env, err := simple.LoadEnvSources(
+		simple.EnvMapSource("defaults", map[string]string{"DB_USER": "admin", "DB_HOST": "default"}),
+		simple.EnvFileSource(base, false),
+		simple.EnvFileSource(local, false),
+		simple.EnvFileSource(filepath.Join(dir, "missing.env"), true),
+		simple.EnvOSSource(),
+		simple.EnvMapSource("overrides", map[string]string{"DB_USER": "root"}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string][2]string{
+		"DB_HOST":        {"local", local},
+		"DB_PORT":        {"6543", "os"},
+		"DB_USER":        {"root", "overrides"},
+		"LAYER_ONLY_KEY": {"base", base},
+	}
+	for key, w := range want {
+		if got := env.Get(key); got != w[0] {
+			t.Errorf("%s: got %q, wanted %q", key, got, w[0])
+		}
+		if got := env.Origin(key); got != w[1] {
+			t.Errorf("%s: got origin %q, wanted %q", key, got, w[1])
+		}
+	}
+
+	// The process environment must stay untouched
+	if _, ok := os.LookupEnv("LAYER_ONLY_KEY"); ok {
+		t.Errorf("LAYER_ONLY_KEY leaked into the process environment")
+	}
+}
+
+// Test the profile cascade of LoadEnvProfile
+func TestLoadEnvProfile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":            "LEVEL=base\nNAME=simple\n",
+		".env.test":       "LEVEL=test\n",
+		".env.test.local": "LEVEL=test-local\n",
+		".env.production": "LEVEL=production\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("SIMPLE_TEST_PROFILE", "test")
+
+	env, err := simple.LoadEnvProfile(dir, "SIMPLE_TEST_PROFILE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env.Get("LEVEL") != "test-local" || env.Get("NAME") != "simple" {
+		t.Errorf("unexpected values: LEVEL=%q NAME=%q", env.Get("LEVEL"), env.Get("NAME"))
+	}
+
+	want := []string{filepath.Join(dir, ".env"), filepath.Join(dir, ".env.test"), filepath.Join(dir, ".env.test.local")}
+	got := env.Files()
+	if len(got) != len(want) {
+		t.Fatalf("got files %v, wanted %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, wanted %q", got[i], want[i])
+		}
+	}
+}
@@ -3,15 +3,19 @@ package test
 
 import (
 	"os"
+	"errors"
 	"testing"
+	"path/filepath"
 
 	"github.com/Bl4omArchie/simple"
+	"github.com/Bl4omArchie/simple/simpletest"
 )
 
 
 // Test OpenEnv function
 // write a temporary .env
 func TestOpenEnv(t *testing.T) {
+	simpletest.EnvSandbox(t)
 	content := "DB_HOST=test\nDB_PASS=password\n"
 	if err := os.WriteFile(".env", []byte(content), 0644); err != nil {
 		t.Fatal(err)
@@ -30,6 +34,7 @@ func TestOpenEnv(t *testing.T) {
 
 // Test OpenEnvFilenames function
 func TestOpenEnvFilenames(t *testing.T) {
+	simpletest.EnvSandbox(t)
 	content := "DB_HOST=test\nDB_PASS=password\n"
 	if err := os.WriteFile("test.env", []byte(content), 0644); err != nil {
 		t.Fatal(err)
@@ -52,3 +57,52 @@ func TestOpenEnvFilenames(t *testing.T) {
 		}
 	}
 }
+
+// Test ReadEnv sentinel errors
+func TestReadEnvErrors(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.env")
+	if err := os.WriteFile(valid, []byte("# comment\nexport DB_HOST=test # inline\nDB_PASS=\"pass\\nword\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.env")
+	if err := os.WriteFile(invalid, []byte("DB_HOST=test\nDB_PASS\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := simple.ReadEnv([]string{valid}, false, "DB_HOST", "DB_PASS")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["DB_HOST"] != "test" || got["DB_PASS"] != "pass\nword" {
+		t.Errorf("unexpected values: %v", got)
+	}
+
+	if _, err := simple.ReadEnv([]string{filepath.Join(dir, "missing.env")}, false); !errors.Is(err, simple.ErrEnvFileNotFound) {
+		t.Errorf("expected ErrEnvFileNotFound, got %v", err)
+	}
+
+	var parseErr *simple.EnvParseError
+	if _, err := simple.ReadEnv([]string{invalid}, false); !errors.Is(err, simple.ErrEnvParse) || !errors.As(err, &parseErr) || parseErr.Line != 2 {
+		t.Errorf("expected ErrEnvParse at line 2, got %v", err)
+	}
+
+	if _, err := simple.ReadEnv([]string{valid}, false, "DB_USER"); !errors.Is(err, simple.ErrEnvKeyMissing) {
+		t.Errorf("expected ErrEnvKeyMissing, got %v", err)
+	}
+
+	// Optional files fall back to the process environment
+	t.Setenv("DB_USER", "admin")
+	got, err = simple.ReadEnv([]string{filepath.Join(dir, "missing.env")}, true, "DB_USER")
+	if err != nil || got["DB_USER"] != "admin" {
+		t.Errorf("got %v, %v, wanted admin", got, err)
+	}
+
+	// Without keys, the files override the whole process environment
+	t.Setenv("DB_HOST", "os")
+	t.Setenv("DB_REF", "base64:c2VjcmV0")
+	got, err = simple.ReadEnv([]string{filepath.Join(dir, "missing.env"), valid}, true)
+	if err != nil || got["DB_USER"] != "admin" || got["DB_HOST"] != "test" || got["DB_REF"] != "base64:c2VjcmV0" {
+		t.Errorf("unexpected values: %v, %v", got, err)
+	}
+}
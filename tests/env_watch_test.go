@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// Test that a modified file triggers the callbacks with the changed keys
+func TestEnvWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("FLAG=off\nSTABLE=1\nREMOVED=x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := simple.NewEnvWatcher(5*time.Millisecond, 20*time.Millisecond, simple.EnvFileSource(path, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := make(chan []string, 1)
+	watcher.OnChange(func(env *simple.Env, changed []string) {
+		changes <- changed
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Run(ctx) }()
+
+	if err := os.WriteFile(path, []byte("FLAG=on\nSTABLE=1\nADDED=y\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Second)
+	os.Chtimes(path, future, future)
+
+	select {
+	case changed := <-changes:
+		if !slices.Equal(changed, []string{"ADDED", "FLAG", "REMOVED"}) {
+			t.Errorf("got changed keys %v", changed)
+		}
+		if watcher.Env().Get("FLAG") != "on" {
+			t.Errorf("got FLAG=%q, wanted on", watcher.Env().Get("FLAG"))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no change detected")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got %v, wanted context.Canceled", err)
+	}
+}
+
+// Test that invalid durations are rejected instead of panicking in Run
+func TestEnvWatcherInvalidDurations(t *testing.T) {
+	source := simple.EnvStringSource("test", "FLAG=on\n")
+	if _, err := simple.NewEnvWatcher(0, time.Millisecond, source); err == nil {
+		t.Errorf("expected an error for a zero interval")
+	}
+	if _, err := simple.NewEnvWatcher(time.Millisecond, -time.Millisecond, source); err == nil {
+		t.Errorf("expected an error for a negative debounce")
+	}
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// `[{"id": 1, "name": "a"}]` compressed with `bzip2 -9`, as compress/bzip2 can't compress
+const recordsBzip2 = "QlpoOTFBWSZTWR2AnXEAAAabgFAEIBAACiYjAAogADFMAAFPQh6I2pDiZixRY4pMrTkIfF3JFOFCQHYCdcQ="
+
+func compressTestFile(t *testing.T, name, content string, newWriter func(io.Writer) io.WriteCloser) string {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := newWriter(&buffer)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return writeTestFile(t, name, buffer.String())
+}
+
+func gzipWriter(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+func zlibWriter(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+
+// Test that compound extensions are decompressed then parsed by their inner extension
+func TestLoadFileCompressed(t *testing.T) {
+	paths := []string{
+		compressTestFile(t, "records.json.gz", `[{"id": 1, "name": "a"}]`, gzipWriter),
+		compressTestFile(t, "records.yaml.zz", "- id: 1\n  name: a\n", zlibWriter),
+		compressTestFile(t, "records.csv.GZ", "id,name\n1,a\n", gzipWriter),
+	}
+	data, _ := base64.StdEncoding.DecodeString(recordsBzip2)
+	paths = append(paths, writeTestFile(t, "records.json.bz2", string(data)))
+
+	for _, path := range paths {
+		records, err := simple.LoadFile[Record](path, 0, true)
+		if err != nil || len(records) != 1 || records[0].Name != "a" {
+			t.Errorf("%s: unexpected result %+v, %v", filepath.Base(path), records, err)
+		}
+	}
+
+	streamed, err := collectRecords(t, context.Background(), paths[0], 0)
+	if err != nil || len(streamed) != 1 {
+		t.Errorf("unexpected streamed result %+v, %v", streamed, err)
+	}
+
+	content, _ := os.ReadFile(paths[0])
+	records, err := simple.LoadReader[Record](bytes.NewReader(content), "json.gz", 0, false)
+	if err != nil || len(records) != 1 {
+		t.Errorf("unexpected reader result %+v, %v", records, err)
+	}
+}
+
+// Test that decompression stops at MaxDecompressedSize
+func TestLoadFileDecompressionBomb(t *testing.T) {
+	defer func(size int64) { simple.MaxDecompressedSize = size }(simple.MaxDecompressedSize)
+	simple.MaxDecompressedSize = 1024
+
+	path := compressTestFile(t, "bomb.json.gz", "["+strings.Repeat(`{"id": 1},`, 1000)+`{"id": 1}]`, gzipWriter)
+	if _, err := simple.LoadFile[Record](path, 0, false); !errors.Is(err, simple.ErrDecompressedTooLarge) {
+		t.Errorf("expected ErrDecompressedTooLarge, got %v", err)
+	}
+
+	var streamErr error
+	for _, err := range simple.StreamFile[Record](context.Background(), path, 0, false) {
+		streamErr = err
+	}
+	if !errors.Is(streamErr, simple.ErrDecompressedTooLarge) {
+		t.Errorf("expected ErrDecompressedTooLarge when streaming, got %v", streamErr)
+	}
+
+	path = writeTestFile(t, "corrupted.json.gz", "not gzip")
+	if _, err := simple.LoadFile[Record](path, 0, false); err == nil {
+		t.Errorf("expected a decompression error")
+	}
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// Test the detectors on content without a known extension
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		format   string
+		detector string
+	}{
+		{"data.json", `not read`, "json", "extension"},
+		{"data.yml", `not read`, "yaml", "alias"},
+		{"config", "\xEF\xBB\xBF  {\"id\": 1}", "json", "json"},
+		{"config", "[\n  {\"id\": 1},\n  {\"id\": 2", "json", "json"},
+		{"export.json.txt", `[{"id": 1}]`, "json", "json"},
+		{"events", "{\"id\": 1}\n{\"id\": 2}\n", "jsonl", "json-lines"},
+		{"feed", "<?xml version=\"1.0\"?>\n<record/>", "xml", "xml-declaration"},
+		{"config", "---\nid: 1\n", "yaml", "yaml-marker"},
+		{"config", "# comment\nid: 1\nname: a\n", "yaml", "yaml-key"},
+		{"config", "- id: 1\n", "yaml", "yaml-key"},
+		{"config", "[server]\nport = 80\n", "toml", "toml-table"},
+		{"config", "[[servers]]\nport = 80\n", "toml", "toml-table"},
+		{"config", "id = 1\n", "toml", "toml-key"},
+		{"export", "id,name\n1,a\n", "csv", "delimited"},
+		{"export", "id\tname\n1\ta\n", "tsv", "delimited"},
+	}
+	for _, test := range tests {
+		detection, err := simple.DetectFormat(test.name, []byte(test.content))
+		if err != nil {
+			t.Errorf("%s %q: unexpected error: %v", test.name, test.content, err)
+			continue
+		}
+		if detection.Format != test.format || detection.Detector != test.detector {
+			t.Errorf("%s %q: got %s, wanted %s by %s", test.name, test.content, detection, test.format, test.detector)
+		}
+	}
+
+	if _, err := simple.DetectFormat("notes.txt", []byte("hello world")); err == nil {
+		t.Errorf("expected an unsupported format error")
+	}
+}
+
+// Test that loading functions detect, alias or force the format
+func TestLoadFileDetection(t *testing.T) {
+	path := writeTestFile(t, "records", "- id: 1\n- id: 2\n")
+	if records, err := simple.LoadFile[Record](path, 0, false); err != nil || len(records) != 2 {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+
+	path = writeTestFile(t, "records.yml", "- id: 1\n")
+	if records, err := simple.LoadFile[Record](path, 0, false); err != nil || len(records) != 1 {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+
+	path = writeTestFile(t, "records.txt", "id,name\n1,a\n")
+	if records, err := simple.LoadFileAs[Record](path, "csv", 0, false); err != nil || len(records) != 1 || records[0].Name != "a" {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+
+	path = writeTestFile(t, "records.dat", "{\"id\": 1}\n{\"id\": 2}\n{\"id\": 3}\n")
+	records, err := collectRecords(t, context.Background(), path, 0)
+	if err != nil || len(records) != 3 {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+}
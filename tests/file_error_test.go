@@ -0,0 +1,105 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+type Server struct {
+	Name  string   `json:"name" yaml:"name" toml:"name" xml:"name" validate:"required"`
+	Port  int      `json:"port" yaml:"port" toml:"port" xml:"port" validate:"gt=0"`
+	Hosts []string `json:"hosts" yaml:"hosts" toml:"hosts" xml:"host" validate:"dive,hostname"`
+}
+
+// Test that every failing field of every element is reported with its location
+func TestLoadErrorValidation(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    []simple.LoadError
+	}{
+		"servers.json": {
+			"[\n  {\"name\": \"a\", \"port\": 80},\n  {\"name\": \"b\",\n   \"port\": 0,\n   \"hosts\": [\"ok\", \"not valid\"]}\n]",
+			[]simple.LoadError{
+				{Element: 1, Path: "/port", Tag: "gt", Line: 4, Column: 4},
+				{Element: 1, Path: "/hosts/1", Tag: "hostname", Line: 5, Column: 20},
+			},
+		},
+		"servers.yaml": {
+			"- name: a\n  port: 80\n- port: 0\n",
+			[]simple.LoadError{
+				{Element: 1, Path: "/name", Tag: "required", Line: 3, Column: 3},
+				{Element: 1, Path: "/port", Tag: "gt", Line: 3, Column: 3},
+			},
+		},
+		"server.toml": {
+			"name = \"a\"\n\nport = -1\n",
+			[]simple.LoadError{{Element: 0, Path: "/port", Tag: "gt", Line: 3, Column: 1}},
+		},
+		"server.xml": {
+			"<server>\n  <name>a</name>\n  <port>0</port>\n</server>",
+			[]simple.LoadError{{Element: 0, Path: "/port", Tag: "gt", Line: 3, Column: 3}},
+		},
+	}
+
+	for name, test := range tests {
+		_, err := simple.LoadFile[Server](writeTestFile(t, name, test.content), 0, true)
+		var errs simple.LoadErrors
+		if !errors.As(err, &errs) {
+			t.Errorf("%s: expected LoadErrors, got %v", name, err)
+			continue
+		}
+		if len(errs) != len(test.want) {
+			t.Errorf("%s: got %d errors, wanted %d:\n%s", name, len(errs), len(test.want), errs.Report())
+			continue
+		}
+		for i, want := range test.want {
+			got := errs[i]
+			if got.Element != want.Element || got.Path != want.Path || got.Tag != want.Tag || got.Line != want.Line || got.Column != want.Column {
+				t.Errorf("%s: got %q, wanted %+v", name, got, want)
+			}
+		}
+	}
+}
+
+// Test that parse errors carry their line and column
+func TestLoadErrorParse(t *testing.T) {
+	tests := map[string]struct {
+		content      string
+		line, column int
+	}{
+		"servers.json": {"[\n  {\"name\": \"a\",\n   \"port\": \"80\"}\n]", 3, 16},
+		"broken.json":  {"{\n  \"name\": \"a\",,\n}", 2, 15},
+		"servers.yaml": {"name: a\nport: [\n", 2, 0},
+		"server.toml":  {"name = \"a\"\nport = \n", 3, 1},
+		"server.xml":   {"<server>\n<name>a</nom>\n</server>", 2, 0},
+	}
+	for name, test := range tests {
+		_, err := simple.LoadFile[Server](writeTestFile(t, name, test.content), 0, false)
+		var loadErr *simple.LoadError
+		if !errors.As(err, &loadErr) {
+			t.Errorf("%s: expected a LoadError, got %v", name, err)
+			continue
+		}
+		if loadErr.Line != test.line || loadErr.Column != test.column {
+			t.Errorf("%s: got line %d column %d, wanted line %d column %d (%v)", name, loadErr.Line, loadErr.Column, test.line, test.column, err)
+		}
+	}
+}
+
+// Test the readable report
+func TestLoadErrorReport(t *testing.T) {
+	_, err := simple.LoadFile[Server](writeTestFile(t, "servers.yaml", "- port: 0\n"), 0, true)
+	var errs simple.LoadErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected LoadErrors, got %v", err)
+	}
+	report := errs.Report()
+	for _, want := range []string{"2 error(s)", "element 0, /name, line 1, column 3: failed on the 'required' tag", "/port"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report doesn't contain %q:\n%s", want, report)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+// Test that every encoder writes a file read back by LoadFile
+func TestSaveFile(t *testing.T) {
+	records := []Record{{ID: 1, Name: "a"}, {ID: 2, Name: "b,c"}}
+	for _, ext := range []string{"json", "yaml", "xml", "csv", "tsv", "jsonl", "ndjson"} {
+		path := filepath.Join(t.TempDir(), "records."+ext)
+		if err := simple.SaveFile(path, records, simple.SaveOptions{}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", ext, err)
+		}
+
+		loaded, err := simple.LoadFile[Record](path, 0, false)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", ext, err)
+		}
+		if len(loaded) != 2 || loaded[0].ID != 1 || loaded[1].Name != "b,c" {
+			t.Errorf("%s: unexpected records %+v", ext, loaded)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "contacts.xml")
+	contacts := []Contact{{Name: "a"}, {Name: "b"}}
+	if err := simple.SaveFile(path, contacts, simple.SaveOptions{}); err != nil {
+		t.Fatalf("xml: unexpected error: %v", err)
+	}
+	if loaded, err := simple.LoadFile[Contact](path, 0, false); err != nil || len(loaded) != 2 || loaded[1].Name != "b" {
+		t.Errorf("xml: unexpected contacts %+v, %v", loaded, err)
+	}
+	if err := simple.SaveFile(path, []Record{}, simple.SaveOptions{}); err != nil {
+		t.Fatalf("xml: unexpected error: %v", err)
+	}
+	if loaded, err := simple.LoadFile[Record](path, 0, false); err != nil || len(loaded) != 0 {
+		t.Errorf("xml: expected no records, got %+v, %v", loaded, err)
+	}
+
+	path = filepath.Join(t.TempDir(), "record.toml")
+	if err := simple.SaveFile(path, records[:1], simple.SaveOptions{}); err != nil {
+		t.Fatalf("toml: unexpected error: %v", err)
+	}
+	if loaded, err := simple.LoadFile[Record](path, 0, false); err != nil || len(loaded) != 1 || loaded[0].Name != "a" {
+		t.Errorf("toml: unexpected records %+v, %v", loaded, err)
+	}
+	if err := simple.SaveFile(path, records, simple.SaveOptions{}); err == nil {
+		t.Errorf("toml: expected an error for several elements")
+	}
+}
+
+// Test the permissions, the validation and that an invalid file is never written
+func TestSaveFileOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.json")
+	if err := simple.SaveFile(path, []Record{{ID: 1}}, simple.SaveOptions{Perm: 0600, Validation: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected 0600 permissions, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	if err := simple.SaveFile(path, []Record{{ID: 2}, {ID: 0}}, simple.SaveOptions{Validation: true}); err == nil {
+		t.Errorf("expected a validation error")
+	}
+	if loaded, err := simple.LoadFile[Record](path, 0, false); err != nil || len(loaded) != 1 || loaded[0].ID != 1 {
+		t.Errorf("expected the previous file to be kept, got %+v, %v", loaded, err)
+	}
+
+	if err := simple.SaveFile(filepath.Join(t.TempDir(), "records.bin"), []Record{{ID: 1}}, simple.SaveOptions{}); err == nil {
+		t.Errorf("expected an unsupported format error")
+	}
+}
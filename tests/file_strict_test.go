@@ -0,0 +1,143 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+type StrictServer struct {
+	Name    string            `json:"name" yaml:"name" toml:"name" xml:"name" csv:"name"`
+	Port    int               `json:"port" yaml:"port" toml:"port" xml:"port,attr" csv:"port"`
+	Labels  map[string]string `json:"labels" yaml:"labels" toml:"labels" xml:"-" csv:"-"`
+	Backend *struct {
+		Host string `json:"host" yaml:"host" toml:"host" xml:"host"`
+	} `json:"backend" yaml:"backend" toml:"backend" xml:"backend" csv:"-"`
+}
+
+// Test that unknown fields and duplicate keys are reported with their path in every format
+func TestLoadFileStrict(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    []simple.LoadError
+	}{
+		"servers.json": {
+			"[{\"name\": \"a\", \"labels\": {\"x\": \"1\", \"x\": \"2\"}},\n {\"name\": \"b\", \"backend\": {\"hots\": \"h\"}}]",
+			[]simple.LoadError{
+				{Element: 0, Path: "/labels/x", Line: 1, Column: 37, Err: simple.ErrDuplicateKey},
+				{Element: 1, Path: "/backend/hots", Line: 2, Column: 28, Err: simple.ErrUnknownField},
+			},
+		},
+		"server.yaml": {
+			"defaults: &defaults\n  name: a\nserver:\n  <<: *defaults\n  nmae: b\n",
+			nil,
+		},
+		"servers.yaml": {
+			"- name: a\n  Port: 80\n",
+			[]simple.LoadError{{Element: 0, Path: "/Port", Line: 2, Column: 3, Err: simple.ErrUnknownField}},
+		},
+		"server.toml": {
+			"name = \"a\"\n[backend]\nhost = \"h\"\nport = 1\n",
+			[]simple.LoadError{{Element: 0, Path: "/backend/port", Line: 4, Column: 1, Err: simple.ErrUnknownField}},
+		},
+		"server.xml": {
+			"<server port=\"80\" debug=\"true\">\n  <name>a</name>\n  <name>b</name>\n  <backend><host>h</host></backend>\n</server>",
+			[]simple.LoadError{
+				{Element: 0, Path: "/debug", Line: 1, Column: 1, Err: simple.ErrUnknownField},
+				{Element: 0, Path: "/name", Line: 3, Column: 3, Err: simple.ErrDuplicateKey},
+			},
+		},
+		"servers.csv": {
+			"name,port,nmae,name\na,80,b,c\n",
+			[]simple.LoadError{
+				{Element: -1, Path: "/nmae", Line: 1, Column: 3, Err: simple.ErrUnknownField},
+				{Element: -1, Path: "/name", Line: 1, Column: 4, Err: simple.ErrDuplicateKey},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		path := writeTestFile(t, name, test.content)
+		if name == "server.yaml" {
+			// merged keys are known, the typo isn't
+			type Root struct {
+				Defaults StrictServer `yaml:"defaults"`
+				Server   StrictServer `yaml:"server"`
+			}
+			_, err := simple.LoadFileStrict[Root](path, 0, false)
+			var errs simple.LoadErrors
+			if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "/server/nmae" || errs[0].Line != 5 {
+				t.Errorf("%s: expected an unknown field at /server/nmae, got %v", name, err)
+			}
+			continue
+		}
+
+		if _, err := simple.LoadFile[StrictServer](path, 0, false); err != nil {
+			t.Errorf("%s: unexpected error without strict mode: %v", name, err)
+		}
+
+		_, err := simple.LoadFileStrict[StrictServer](path, 0, false)
+		var errs simple.LoadErrors
+		if !errors.As(err, &errs) {
+			t.Errorf("%s: expected LoadErrors, got %v", name, err)
+			continue
+		}
+		if len(errs) != len(test.want) {
+			t.Errorf("%s: got %d errors, wanted %d:\n%s", name, len(errs), len(test.want), errs.Report())
+			continue
+		}
+		for i, want := range test.want {
+			got := errs[i]
+			if got.Element != want.Element || got.Path != want.Path || got.Line != want.Line || got.Column != want.Column || !errors.Is(got, want.Err) {
+				t.Errorf("%s: got %q (element %d), wanted %+v", name, got, got.Element, want)
+			}
+		}
+	}
+}
+
+// Test that a valid file and json lines are accepted, and that strict json lines report the line
+func TestLoadFileStrictJSONLines(t *testing.T) {
+	path := writeTestFile(t, "servers.json", `{"NAME": "a", "port": 80, "labels": {"x": "1"}, "backend": {"host": "h"}}`)
+	if servers, err := simple.LoadFileStrict[StrictServer](path, 0, false); err != nil || len(servers) != 1 || servers[0].Backend.Host != "h" {
+		t.Errorf("unexpected result %+v, %v", servers, err)
+	}
+
+	path = writeTestFile(t, "servers.jsonl", "{\"name\": \"a\"}\n{\"name\": \"b\", \"prot\": 80}\n")
+	_, err := simple.LoadFileStrict[StrictServer](path, 0, false)
+	var lineErr *simple.JSONLineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 || !errors.Is(err, simple.ErrUnknownField) {
+		t.Errorf("expected an unknown field at line 2, got %v", err)
+	}
+}
+
+// Test the strict variants of LoadFS and LoadReader, and the fallback on FileRegistry for custom formats
+func TestLoadStrictSources(t *testing.T) {
+	fsys := fstest.MapFS{"servers.json": {Data: []byte(`[{"name": "a", "prot": 80}]`)}}
+	if _, err := simple.LoadFSStrict[StrictServer](fsys, "servers.json", 0, false); !errors.Is(err, simple.ErrUnknownField) {
+		t.Errorf("LoadFSStrict: expected an unknown field, got %v", err)
+	}
+	if _, err := simple.LoadReaderStrict[StrictServer](strings.NewReader("name: a\nprot: 80\n"), "yaml", 0, false); !errors.Is(err, simple.ErrUnknownField) {
+		t.Errorf("LoadReaderStrict: expected an unknown field, got %v", err)
+	}
+
+	simple.FileRegistry["psv"] = simple.CSVFormat{Comma: '|'}.Unmarshal
+	defer delete(simple.FileRegistry, "psv")
+	path := writeTestFile(t, "servers.psv", "name|port\na|80\n")
+	if servers, err := simple.LoadFileStrict[StrictServer](path, 0, false); err != nil || len(servers) != 1 || servers[0].Port != 80 {
+		t.Errorf("unexpected result %+v, %v", servers, err)
+	}
+
+	path = writeTestFile(t, "records.xml", "<records><record><id>1</id></record><record><id>2</id></record></records>")
+	if records, err := simple.LoadFileStrict[Record](path, 0, false); err != nil || len(records) != 2 {
+		t.Errorf("unexpected xml result %+v, %v", records, err)
+	}
+	path = writeTestFile(t, "records.xml", "<records>\n  <record><id>1</id></record>\n  <record><id>2</id><nmae>b</nmae></record>\n</records>")
+	_, err := simple.LoadFileStrict[Record](path, 0, false)
+	var errs simple.LoadErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Element != 1 || errs[0].Path != "/nmae" || errs[0].Line != 3 || errs[0].Column != 21 {
+		t.Errorf("expected an unknown field in element 1, got %v", err)
+	}
+}
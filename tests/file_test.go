@@ -0,0 +1,314 @@
+package test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Bl4omArchie/simple"
+)
+
+type Record struct {
+	XMLName xml.Name `json:"-" yaml:"-" toml:"-" csv:"-" xml:"record"`
+	ID      int      `json:"id" yaml:"id" xml:"id" validate:"gt=0"`
+	Name    string   `json:"name" yaml:"name" xml:"name"`
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func collectRecords(t *testing.T, ctx context.Context, path string, limit int) ([]Record, error) {
+	t.Helper()
+	var records []Record
+	for record, err := range simple.StreamFile[Record](ctx, path, limit, true) {
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Test that every format yields its elements in order
+func TestStreamFile(t *testing.T) {
+	files := map[string]string{
+		"records.json": `[{"id": 1, "name": "a"}, {"id": 2, "name": "b"}, {"id": 3, "name": "c"}]`,
+		"records.yaml": "- id: 1\n  name: a\n- id: 2\n  name: b\n---\nid: 3\nname: c\n",
+		"records.xml":  "<records><record><id>1</id><name>a</name></record><record><id>2</id><name>b</name></record><record><id>3</id><name>c</name></record></records>",
+		"records.toml": "id = 1\nname = \"a\"\n",
+	}
+	for name, content := range files {
+		records, err := collectRecords(t, context.Background(), writeTestFile(t, name, content), 0)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		want := 3
+		if name == "records.toml" {
+			want = 1
+		}
+		if len(records) != want || records[0].ID != 1 || records[0].Name != "a" {
+			t.Errorf("%s: unexpected records %+v", name, records)
+		}
+	}
+}
+
+// Test that reading stops at the limit, before the malformed tail of the file
+func TestStreamFileLimit(t *testing.T) {
+	path := writeTestFile(t, "records.json", `[{"id": 1}, {"id": 2}, {"id": 3}, not json`)
+	records, err := collectRecords(t, context.Background(), path, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Errorf("got %d records, wanted 2", len(records))
+	}
+
+	if _, err := collectRecords(t, context.Background(), path, 0); err == nil {
+		t.Errorf("expected a parse error without limit")
+	}
+}
+
+// Test that a cancelled context and an invalid element end the sequence
+func TestStreamFileErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	path := writeTestFile(t, "records.json", `[{"id": 1}]`)
+	if _, err := collectRecords(t, ctx, path, 0); err != context.Canceled {
+		t.Errorf("got %v, wanted context.Canceled", err)
+	}
+
+	path = writeTestFile(t, "records.json", `[{"id": 1}, {"id": 0}, {"id": 3}]`)
+	records, err := collectRecords(t, context.Background(), path, 0)
+	var loadErr *simple.LoadError
+	if !errors.As(err, &loadErr) || loadErr.Element != 1 || loadErr.Path != "/id" || loadErr.Tag != "gt" || len(records) != 1 {
+		t.Errorf("expected a validation error on element 1, got %v with %d records", err, len(records))
+	}
+}
+
+type Measure struct {
+	Sensor string    `csv:"sensor" validate:"required"`
+	Value  float64   `csv:"value"`
+	Count  int       `csv:"count" validate:"gte=0"`
+	Active bool      `csv:"active"`
+	At     time.Time `csv:"at"`
+	Limit  *int      `csv:"limit"`
+	Note   string    `csv:"-"`
+}
+
+type Account struct {
+	User string `csv:"user"`
+	Pin  int    `csv:"pin" secret:"true"`
+}
+
+// Test the header mapping and the conversions of csv and tsv files
+func TestLoadFileCSV(t *testing.T) {
+	path := writeTestFile(t, "measures.csv", "count,sensor,value,active,at,limit,note\n3,\"a,1\",1.5,true,2026-10-18T10:00:00Z,,x\n4,b,2,false,2026-10-18T11:00:00Z,7,y\n")
+	measures, err := simple.LoadFile[Measure](path, 0, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(measures) != 2 {
+		t.Fatalf("got %d measures, wanted 2", len(measures))
+	}
+	first := measures[0]
+	if first.Sensor != "a,1" || first.Value != 1.5 || first.Count != 3 || !first.Active || first.At.Hour() != 10 || first.Limit != nil || first.Note != "" {
+		t.Errorf("unexpected first measure: %+v", first)
+	}
+	if measures[1].Limit == nil || *measures[1].Limit != 7 {
+		t.Errorf("expected a limit of 7, got %v", measures[1].Limit)
+	}
+
+	path = writeTestFile(t, "measures.csv", "\xEF\xBB\xBFsensor,count\ne,5\n")
+	if measures, err := simple.LoadFile[Measure](path, 0, true); err != nil || len(measures) != 1 || measures[0].Sensor != "e" {
+		t.Errorf("unexpected result with a BOM %+v, %v", measures, err)
+	}
+
+	path = writeTestFile(t, "measures.tsv", "sensor\tvalue\nc\t0.5\n")
+	if measures, err := simple.LoadFile[Measure](path, 0, false); err != nil || len(measures) != 1 || measures[0].Value != 0.5 {
+		t.Errorf("unexpected tsv result %+v, %v", measures, err)
+	}
+}
+
+// Test a custom delimiter with comments
+func TestLoadFileCSVFormat(t *testing.T) {
+	simple.FileRegistry["psv"] = simple.CSVFormat{Comma: '|', Comment: '#', TimeLayout: time.DateOnly}.Unmarshal
+	defer delete(simple.FileRegistry, "psv")
+
+	path := writeTestFile(t, "measures.psv", "# export\nsensor|at\nd|2026-10-18\n")
+	measures, err := simple.LoadFile[Measure](path, 0, false)
+	if err != nil || len(measures) != 1 || measures[0].At.Day() != 18 {
+		t.Errorf("unexpected result %+v, %v", measures, err)
+	}
+}
+
+// Test that conversion and validation errors give the line and the column
+func TestLoadFileCSVErrors(t *testing.T) {
+	path := writeTestFile(t, "measures.csv", "sensor,count\na,1\nb,two\n")
+	_, err := simple.LoadFile[Measure](path, 0, false)
+	var csvErr *simple.CSVError
+	if !errors.As(err, &csvErr) || csvErr.Line != 3 || csvErr.Column != 2 || csvErr.Header != "count" {
+		t.Errorf("expected a conversion error at line 3 column 2, got %v", err)
+	}
+	if strings.Count(err.Error(), "line 3") != 1 {
+		t.Errorf("expected the location once, got %v", err)
+	}
+
+	path = writeTestFile(t, "accounts.csv", "user,pin\na,12x4\n")
+	_, err = simple.LoadFile[Account](path, 0, false)
+	if !errors.As(err, &csvErr) || csvErr.Header != "pin" || strings.Contains(err.Error(), "12x4") {
+		t.Errorf("expected a conversion error without the secret value, got %v", err)
+	}
+
+	path = writeTestFile(t, "measures.csv", "sensor,count\na,1\nb,-1\n")
+	_, err = simple.LoadFile[Measure](path, 0, true)
+	if !errors.As(err, &csvErr) || csvErr.Line != 3 || csvErr.Column != 2 {
+		t.Errorf("expected a validation error at line 3 column 2, got %v", err)
+	}
+}
+
+// Test json lines with limit and validation, blank lines are ignored
+func TestLoadFileJSONLines(t *testing.T) {
+	path := writeTestFile(t, "records.ndjson", "{\"id\": 1, \"name\": \"a\"}\n\n{\"id\": 2, \"name\": \"b\"}\r\n{\"id\": 3}")
+	records, err := simple.LoadFile[Record](path, 2, true)
+	if err != nil || len(records) != 2 || records[1].Name != "b" {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+
+	path = writeTestFile(t, "records.jsonl", "{\"id\": 1}\n{\"id\": 0}\n")
+	_, err = simple.LoadFile[Record](path, 0, true)
+	var lineErr *simple.JSONLineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 {
+		t.Errorf("expected a validation error at line 2, got %v", err)
+	}
+}
+
+// Test that malformed lines abort by default, or are collected and skipped with OnMalformed
+func TestLoadFileJSONLinesMalformed(t *testing.T) {
+	content := "{\"id\": 1}\n{\"id\": \n{\"id\": 3}\n"
+	path := writeTestFile(t, "records.jsonl", content)
+	_, err := simple.LoadFile[Record](path, 0, false)
+	var lineErr *simple.JSONLineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 || strings.Count(err.Error(), "line 2") != 1 {
+		t.Errorf("expected a parse error at line 2, got %v", err)
+	}
+
+	var malformed []*simple.JSONLineError
+	simple.FileRegistry["log"] = simple.JSONLinesFormat{OnMalformed: func(err *simple.JSONLineError) {
+		malformed = append(malformed, err)
+	}}.Unmarshal
+	defer delete(simple.FileRegistry, "log")
+
+	records, err := simple.LoadFile[Record](writeTestFile(t, "records.log", content), 0, false)
+	if err != nil || len(records) != 2 || records[1].ID != 3 {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+	if len(malformed) != 1 || malformed[0].Line != 2 {
+		t.Errorf("expected line 2 to be collected, got %v", malformed)
+	}
+}
+
+// Test that json lines are streamed up to the limit
+func TestStreamFileJSONLines(t *testing.T) {
+	path := writeTestFile(t, "records.jsonl", "{\"id\": 1}\n{\"id\": 2}\nnot json\n")
+	records, err := collectRecords(t, context.Background(), path, 2)
+	if err != nil || len(records) != 2 {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+}
+
+// Test loading from a fs.FS and from a reader, with the same limit and validation as LoadFile
+func TestLoadFSAndReader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"data/records.yaml": {Data: []byte("- id: 1\n- id: 2\n- id: 3\n")},
+		"data/record.json":  {Data: []byte(`{"id": 4, "name": "d"}`)},
+		"data/invalid.json": {Data: []byte(`[{"id": 0}]`)},
+	}
+	if records, err := simple.LoadFS[Record](fsys, "data/records.yaml", 2, true); err != nil || len(records) != 2 {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+	if records, err := simple.LoadFS[Record](fsys, "data/record.json", 0, true); err != nil || len(records) != 1 || records[0].Name != "d" {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+	if _, err := simple.LoadFS[Record](fsys, "data/invalid.json", 0, true); err == nil {
+		t.Errorf("expected a validation error")
+	}
+	if _, err := simple.LoadFS[Record](fsys, "data/missing.json", 0, false); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+
+	records, err := simple.LoadReader[Record](strings.NewReader("id,name\n5,e\n"), "CSV", 0, true)
+	if err != nil || len(records) != 1 || records[0].ID != 5 {
+		t.Errorf("unexpected result %+v, %v", records, err)
+	}
+	if _, err := simple.LoadReader[Record](strings.NewReader("{}"), "bin", 0, false); err == nil {
+		t.Errorf("expected an unsupported format error")
+	}
+}
+
+type Contact struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email"`
+}
+
+// Test xml streaming of a type without XMLName : every child of the root is an element
+func TestStreamFileXMLWithoutName(t *testing.T) {
+	path := writeTestFile(t, "contacts.xml", "<contacts><user><name>a</name></user><user><name>b</name></user></contacts>")
+	var contacts []Contact
+	for contact, err := range simple.StreamFile[Contact](context.Background(), path, 0, false) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		contacts = append(contacts, contact)
+	}
+	if len(contacts) != 2 || contacts[1].Name != "b" {
+		t.Errorf("unexpected contacts %+v", contacts)
+	}
+
+	path = writeTestFile(t, "contact.xml", "<contact><name>c</name><email>c@mail</email></contact>")
+	contacts = nil
+	for contact, err := range simple.StreamFile[Contact](context.Background(), path, 0, false) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		contacts = append(contacts, contact)
+	}
+	if len(contacts) != 1 || contacts[0].Email != "c@mail" {
+		t.Errorf("expected the root element, got %+v", contacts)
+	}
+}
+
+type ServiceConfig struct {
+	Name string `xml:"name"`
+	Port int    `xml:"port"`
+}
+
+// Test that a single struct without XMLName is read from a root of another name, its children being its fields
+func TestLoadFileXMLSingle(t *testing.T) {
+	path := writeTestFile(t, "config.xml", "<config><name>x</name><port>8</port></config>")
+	configs, err := simple.LoadFile[ServiceConfig](path, 0, false)
+	if err != nil || len(configs) != 1 || configs[0].Name != "x" || configs[0].Port != 8 {
+		t.Errorf("unexpected result %+v, %v", configs, err)
+	}
+	configs, err = simple.LoadFileStrict[ServiceConfig](path, 0, false)
+	if err != nil || len(configs) != 1 || configs[0].Port != 8 {
+		t.Errorf("unexpected strict result %+v, %v", configs, err)
+	}
+
+	path = writeTestFile(t, "configs.xml", "<configs><serviceconfig><port>1</port></serviceconfig><serviceconfig><port>2</port></serviceconfig></configs>")
+	if configs, err := simple.LoadFile[ServiceConfig](path, 0, false); err != nil || len(configs) != 2 || configs[1].Port != 2 {
+		t.Errorf("unexpected collection %+v, %v", configs, err)
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+	"gopkg.in/yaml.v3"
+)
+
+type SecretConfig struct {
+	User     string        `json:"user" env:"USER"`
+	Password simple.Secret `json:"password" env:"PASSWORD"`
+	Token    string        `json:"token" env:"TOKEN" secret:"true"`
+	Port     int           `json:"port" env:"PORT" secret:"true"`
+}
+
+// Test that a Secret never leaks through fmt, json, yaml and slog
+func TestSecretRedaction(t *testing.T) {
+	config := SecretConfig{User: "admin", Password: "hunter2", Token: "abc"}
+
+	outputs := []string{
+		fmt.Sprint(config.Password),
+		fmt.Sprintf("%s %v %+v %#v %q %x", config.Password, config.Password, config, config, config.Password, config.Password),
+	}
+	data, _ := json.Marshal(config)
+	outputs = append(outputs, string(data))
+	data, _ = yaml.Marshal(config)
+	outputs = append(outputs, string(data))
+	var logs bytes.Buffer
+	slog.New(slog.NewTextHandler(&logs, nil)).Info("config", "password", config.Password)
+	outputs = append(outputs, logs.String())
+
+	for _, output := range outputs {
+		if strings.Contains(output, "hunter2") {
+			t.Errorf("secret leaked: %s", output)
+		}
+	}
+	if config.Password.Reveal() != "hunter2" {
+		t.Errorf("got %q, wanted hunter2", config.Password.Reveal())
+	}
+}
+
+// Test RedactedDump and redacted env errors
+func TestRedactedDump(t *testing.T) {
+	t.Setenv("SECRET_PASSWORD", "hunter2")
+	t.Setenv("SECRET_TOKEN", "abc")
+	t.Setenv("SECRET_PORT", "not-a-port")
+
+	_, err := simple.LoadEnvInto[SecretConfig]("SECRET_", false)
+	if err == nil || strings.Contains(err.Error(), "not-a-port") {
+		t.Errorf("expected a redacted error, got %v", err)
+	}
+
+	t.Setenv("SECRET_PORT", "80")
+	config, err := simple.LoadEnvInto[SecretConfig]("SECRET_", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.User = "admin"
+
+	want := "User = admin\nPassword = [REDACTED]\nToken = [REDACTED]\nPort = [REDACTED]"
+	if got := simple.RedactedDump(config); got != want {
+		t.Errorf("got:\n%s\nwanted:\n%s", got, want)
+	}
+}
+
+// Test that every format of SaveFile writes a Secret redacted
+func TestSecretSaveFile(t *testing.T) {
+	configs := []SecretConfig{{User: "admin", Password: "hunter2"}}
+	for _, ext := range []string{"json", "yaml", "toml", "xml", "csv", "jsonl"} {
+		path := filepath.Join(t.TempDir(), "config."+ext)
+		if err := simple.SaveFile(path, configs, simple.SaveOptions{}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", ext, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "hunter2") || !strings.Contains(string(data), "[REDACTED]") {
+			t.Errorf("%s: secret not redacted:\n%s", ext, data)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Bl4omArchie/simple"
+	"github.com/Bl4omArchie/simple/simpletest"
+)
+
+// Test that the sandbox restores the environment after the subtest
+func TestEnvSandbox(t *testing.T) {
+	t.Run("sandbox", func(t *testing.T) {
+		simpletest.SetEnvString(t, "SANDBOX_HOST=local\nSANDBOX_PORT=80\n")
+		os.Setenv("SANDBOX_LEAK", "x")
+
+		config, err := simple.LoadEnvInto[struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT"`
+		}]("SANDBOX_", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Host != "local" || config.Port != 80 {
+			t.Errorf("unexpected values: %+v", config)
+		}
+	})
+
+	for _, key := range []string{"SANDBOX_HOST", "SANDBOX_PORT", "SANDBOX_LEAK"} {
+		if _, ok := os.LookupEnv(key); ok {
+			t.Errorf("%s leaked out of the sandbox", key)
+		}
+	}
+}
+
+// Test the in-memory loader in parallel subtests
+func TestLoadEnvInMemory(t *testing.T) {
+	for _, value := range []string{"a", "b", "c"} {
+		t.Run(value, func(t *testing.T) {
+			t.Parallel()
+			env := simpletest.LoadEnv(t, "MEMORY_VALUE="+value+"\n")
+			config, err := simple.BindEnv[struct {
+				Value string `env:"VALUE"`
+			}](env, "MEMORY_", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Value != value {
+				t.Errorf("got %q, wanted %q", config.Value, value)
+			}
+		})
+	}
+}